@@ -0,0 +1,154 @@
+// Package adversary builds inputs which make sorting algorithms do the most
+// work. They are useful for benchmarks and for checking worst-case bounds.
+package adversary
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/macie/sortof"
+)
+
+// Reversed returns numbers from n-1 down to 0. Every pair of them is in
+// the wrong order, so Slowsort makes the most swaps, and neither Miraclesort
+// nor prefix-locking Bogosort has anything to start with.
+func Reversed(n int) []int {
+	x := make([]int, n)
+	for i := range x {
+		x[i] = n - 1 - i
+	}
+
+	return x
+}
+
+// MaxFirst returns n-1 followed by numbers from 0 to n-2. Stalinsort keeps
+// only the first element and deletes all the others.
+func MaxFirst(n int) []int {
+	x := make([]int, n)
+	for i := 1; i < n; i++ {
+		x[i] = i - 1
+	}
+	if n > 0 {
+		x[0] = n - 1
+	}
+
+	return x
+}
+
+// Generate returns a pessimal input of n distinct numbers from 0 to n-1 for
+// the algorithm a. Built-in algorithms have known worst-case inputs:
+//   - bogo: Reversed, because distinct elements have the least chance to be
+//     shuffled into order, and no prefix is locked with
+//     sortof.WithLockedPrefix (after the first shuffle the order of input
+//     does not matter),
+//   - miracle: Reversed, because it is not sorted,
+//   - slow: Reversed, which needs the most swaps,
+//   - stalin: MaxFirst, which leaves a single survivor.
+//
+// For other deterministic algorithms the input is built by sorting with
+// the adversarial Comparator, so sorting the input makes the same
+// comparisons. A context controls cancellation of that sorting.
+func Generate(ctx context.Context, a sortof.Algorithm, n int) ([]int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("cannot generate input of %d elements", n)
+	}
+
+	switch a.Name {
+	case "bogo", "miracle", "slow":
+		return Reversed(n), nil
+	case "stalin":
+		return MaxFirst(n), nil
+	}
+	if !a.Deterministic {
+		return nil, fmt.Errorf("no adversary for randomized algorithm %q", a.Name)
+	}
+
+	c := NewComparator(n)
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i
+	}
+	if _, err := a.Sort(ctx, sortof.WrapSlice(ids, c.Compare)); err != nil {
+		return nil, err
+	}
+
+	return c.Input(), nil
+}
+
+// Comparator is the adversarial comparison function described by
+// M. Douglas McIlroy. It compares n elements identified by numbers from 0 to
+// n-1 and decides their values lazily. All elements start as "gas", which is
+// greater than any value. When two gas elements are compared, one of them is
+// frozen to the least unused value, preferably the one which was compared
+// recently (e.g. a pivot). So the answers reveal as little as possible and
+// the algorithm needs many comparisons.
+//
+// After sorting the identities with Compare, Input returns values which make
+// a deterministic algorithm repeat the same comparisons. Comparator is not
+// safe for concurrent use by multiple goroutines.
+//
+// See: M. Douglas McIlroy. A Killer Adversary for Quicksort. Software:
+// Practice and Experience, 29(4):341-344, 1999.
+type Comparator struct {
+	values    []int // decided values, len(values) for gas
+	frozen    int   // number of elements with decided values
+	candidate int   // gas element compared most recently
+}
+
+// NewComparator returns an adversarial Comparator of n elements.
+func NewComparator(n int) *Comparator {
+	c := &Comparator{values: make([]int, n), candidate: -1}
+	for i := range c.values {
+		c.values[i] = n
+	}
+
+	return c
+}
+
+// Compare compares elements with identities i and j. It returns a negative
+// number when i < j, a positive number when i > j and zero when i == j.
+func (c *Comparator) Compare(i, j int) int {
+	if c.gas(i) && c.gas(j) {
+		if i == c.candidate {
+			c.freeze(i)
+		} else {
+			c.freeze(j)
+		}
+	}
+	if c.gas(i) {
+		c.candidate = i
+	} else if c.gas(j) {
+		c.candidate = j
+	}
+
+	return cmp.Compare(c.values[i], c.values[j])
+}
+
+// Input returns values of elements decided by comparisons. Elements which are
+// still gas get the greatest values, so the result is a permutation of
+// numbers from 0 to n-1 consistent with all answers of Compare.
+func (c *Comparator) Input() []int {
+	x := slices.Clone(c.values)
+	next := c.frozen
+	for i := range x {
+		if x[i] == len(x) {
+			x[i] = next
+			next++
+		}
+	}
+
+	return x
+}
+
+// gas reports whether the value of the element i is not decided yet.
+func (c *Comparator) gas(i int) bool {
+	return c.values[i] == len(c.values)
+}
+
+// freeze decides the value of the element i.
+func (c *Comparator) freeze(i int) {
+	c.values[i] = c.frozen
+	c.frozen++
+}
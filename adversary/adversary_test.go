@@ -0,0 +1,189 @@
+package adversary
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/macie/sortof"
+)
+
+func TestReversed(t *testing.T) {
+	testcases := map[int][]int{
+		0: {},
+		1: {0},
+		4: {3, 2, 1, 0},
+	}
+	for n, want := range testcases {
+		if got := Reversed(n); !slices.Equal(got, want) {
+			t.Errorf("Reversed(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestMaxFirst(t *testing.T) {
+	testcases := map[int][]int{
+		0: {},
+		1: {0},
+		4: {3, 0, 1, 2},
+	}
+	for n, want := range testcases {
+		if got := MaxFirst(n); !slices.Equal(got, want) {
+			t.Errorf("MaxFirst(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	n := 10
+	for _, a := range sortof.Algorithms() {
+		a := a
+		t.Run(a.Name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Generate(ctx, a, n)
+			if err != nil {
+				t.Fatalf("Generate(%v, %v, %d) returns error: %v", ctx, a, n, err)
+			}
+			if !isPermutation(got) {
+				t.Errorf("Generate(%v, %v, %d) = %v, want permutation of numbers from 0 to %d", ctx, a, n, got, n-1)
+			}
+			if slices.IsSorted(got) {
+				t.Errorf("Generate(%v, %v, %d) = %v, want unsorted input", ctx, a, n, got)
+			}
+		})
+	}
+}
+
+func TestGenerateSlowsort(t *testing.T) {
+	ctx := context.Background()
+	a, _ := sortof.Lookup("slow")
+	n := 10
+	x, _ := Generate(ctx, a, n)
+
+	var stats sortof.Stats
+	if err := sortof.SlowsortWith(ctx, x, cmp.Compare[int], sortof.WithStats(&stats)); err != nil {
+		t.Fatalf("SlowsortWith(%v, %v, cmp.Compare) returns error: %v", ctx, x, err)
+	}
+	if want := int64(n * (n - 1) / 2); stats.Swaps != want {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare) makes %d swaps, want %d", ctx, x, stats.Swaps, want)
+	}
+}
+
+func TestGenerateStalinsort(t *testing.T) {
+	ctx := context.Background()
+	a, _ := sortof.Lookup("stalin")
+	x, _ := Generate(ctx, a, 10)
+
+	got, err := sortof.Stalinsort(ctx, x)
+	if err != nil {
+		t.Fatalf("Stalinsort(%v, %v) returns error: %v", ctx, x, err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Stalinsort(%v, %v) = %v, want single survivor", ctx, x, got)
+	}
+}
+
+func TestGenerateComparator(t *testing.T) {
+	ctx := context.Background()
+	testcases := []struct {
+		n    int
+		want int // the least number of comparisons
+	}{
+		{64, 64 * 64 / 4},
+		{128, 128 * 128 / 4},
+	}
+	for _, tc := range testcases {
+		var comparisons int
+		a := sortof.Algorithm{
+			Name:          "quick",
+			Deterministic: true,
+			Sort: func(ctx context.Context, data sortof.Interface, opts ...sortof.Option) (int, error) {
+				comparisons = 0
+				quicksort(countingInterface{data, &comparisons})
+				return data.Len(), nil
+			},
+		}
+
+		x, err := Generate(ctx, a, tc.n)
+		if err != nil {
+			t.Fatalf("Generate(%v, %v, %d) returns error: %v", ctx, a, tc.n, err)
+		}
+		if !isPermutation(x) {
+			t.Errorf("Generate(%v, %v, %d) = %v, want permutation of numbers from 0 to %d", ctx, a, tc.n, x, tc.n-1)
+		}
+		adversaryComparisons := comparisons
+
+		got, _ := sortof.SortFunc(ctx, a, slices.Clone(x), cmp.Compare[int])
+		if !slices.IsSorted(got) {
+			t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) cannot sort; got %v", ctx, a, x, got)
+		}
+		if comparisons != adversaryComparisons || comparisons < tc.want {
+			t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) makes %d comparisons, adversary makes %d, want at least %d",
+				ctx, a, x, comparisons, adversaryComparisons, tc.want)
+		}
+	}
+}
+
+func TestGenerateRandomized(t *testing.T) {
+	ctx := context.Background()
+	a := sortof.Algorithm{
+		Name: "random",
+		Sort: func(ctx context.Context, data sortof.Interface, opts ...sortof.Option) (int, error) {
+			return data.Len(), nil
+		},
+	}
+
+	if _, err := Generate(ctx, a, 5); err == nil {
+		t.Errorf("Generate(%v, %v, 5) returns no error for randomized algorithm", ctx, a)
+	}
+}
+
+// isPermutation reports whether x contains numbers from 0 to len(x)-1.
+func isPermutation(x []int) bool {
+	sorted := slices.Clone(x)
+	slices.Sort(sorted)
+	for i, v := range sorted {
+		if v != i {
+			return false
+		}
+	}
+
+	return true
+}
+
+// countingInterface counts comparisons of the wrapped data.
+type countingInterface struct {
+	sortof.Interface
+	comparisons *int
+}
+
+func (c countingInterface) Compare(i, j int) int {
+	*c.comparisons++
+	return c.Interface.Compare(i, j)
+}
+
+// quicksort sorts data with quicksort, which uses the middle element as
+// a pivot. It makes O(n*log(n)) comparisons on average.
+func quicksort(data sortof.Interface) {
+	var sort func(lo, hi int)
+	sort = func(lo, hi int) {
+		if hi-lo < 2 {
+			return
+		}
+		data.Swap((lo+hi)/2, hi-1)
+		pivot := lo
+		for i := lo; i < hi-1; i++ {
+			if data.Compare(i, hi-1) < 0 {
+				data.Swap(i, pivot)
+				pivot++
+			}
+		}
+		data.Swap(pivot, hi-1)
+		sort(lo, pivot)
+		sort(pivot+1, hi)
+	}
+	sort(0, data.Len())
+}
@@ -0,0 +1,215 @@
+package sortof
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Interface is a collection which can be sorted by an Algorithm. It is
+// similar to sort.Interface, but elements are compared with three-way
+// comparison: Compare(i, j) should return a negative number when the element
+// with index i is less than the element with index j, a positive number when
+// it is greater and zero when both are equal.
+type Interface interface {
+	Len() int
+	Compare(i, j int) int
+	Swap(i, j int)
+}
+
+// WrapSlice returns an Interface of the slice x, which elements are compared
+// by the cmp function.
+func WrapSlice[S ~[]E, E any](x S, cmp func(a, b E) int) Interface {
+	return sliceInterface[S, E]{x: x, cmp: cmp}
+}
+
+// sliceInterface is an Interface of a slice.
+type sliceInterface[S ~[]E, E any] struct {
+	x   S
+	cmp func(a, b E) int
+}
+
+func (s sliceInterface[S, E]) Len() int             { return len(s.x) }
+func (s sliceInterface[S, E]) Compare(i, j int) int { return s.cmp(s.x[i], s.x[j]) }
+func (s sliceInterface[S, E]) Swap(i, j int)        { s.x[i], s.x[j] = s.x[j], s.x[i] }
+
+// Algorithm is a sorting algorithm together with its description.
+type Algorithm struct {
+	Name          string // short name, e.g. "bogo"
+	Title         string // human-readable name, e.g. "Bogosort"
+	Filtering     bool   // whether the algorithm deletes elements instead of moving them
+	Stable        bool   // whether equal elements always keep their original order
+	Deterministic bool   // whether the algorithm makes no random choices
+	Complexity    string // average time complexity
+
+	// Sort sorts data in ascending order with settings from opts (options
+	// not applicable to the algorithm are ignored) and returns the number of
+	// sorted elements. Filtering algorithms move the remaining elements to
+	// the beginning of data, so the result is data[:n]. Other algorithms
+	// return data.Len(). A context controls cancellation.
+	Sort func(ctx context.Context, data Interface, opts ...Option) (n int, err error)
+
+	// Estimate returns the expected work of sorting n elements in random
+	// order with settings from opts, where duplicates are sizes of groups of
+	// equal elements (see Estimate). It is nil if the work is unknown.
+	Estimate func(n int, duplicates []int, opts ...Option) Estimation
+}
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	return a.Name
+}
+
+// SortFunc sorts the slice x of any type in ascending order as determined by
+// the cmp function with the algorithm a and returns the sorted part of x.
+// For filtering algorithms it is a prefix of x.
+func SortFunc[S ~[]E, E any](ctx context.Context, a Algorithm, x S, cmp func(a, b E) int, opts ...Option) (S, error) {
+	n, err := a.Sort(ctx, WrapSlice(x, cmp), opts...)
+
+	return x[:n], err
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Algorithm)
+)
+
+// Register makes the algorithm available by its name in Lookup and
+// Algorithms. It panics if the name is empty or already registered, or if
+// a.Sort is nil.
+func Register(a Algorithm) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if a.Name == "" {
+		panic("sortof: Register algorithm with empty name")
+	}
+	if a.Sort == nil {
+		panic(fmt.Sprintf("sortof: Register algorithm %q with nil Sort", a.Name))
+	}
+	if _, dup := registry[a.Name]; dup {
+		panic(fmt.Sprintf("sortof: Register called twice for algorithm %q", a.Name))
+	}
+	registry[a.Name] = a
+}
+
+// Lookup returns the registered algorithm with the given name.
+func Lookup(name string) (Algorithm, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	a, ok := registry[name]
+	return a, ok
+}
+
+// Algorithms returns all registered algorithms sorted by name.
+func Algorithms() []Algorithm {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	algorithms := make([]Algorithm, 0, len(registry))
+	for _, a := range registry {
+		algorithms = append(algorithms, a)
+	}
+	slices.SortFunc(algorithms, func(a, b Algorithm) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return algorithms
+}
+
+func init() {
+	Register(Algorithm{
+		Name:       "bogo",
+		Title:      "Bogosort",
+		Complexity: "O(n·n!)",
+		Sort: func(ctx context.Context, data Interface, opts ...Option) (int, error) {
+			return sortIndices(data, func(p []int, cmp func(a, b int) int) error {
+				return BogosortWith(ctx, p, cmp, opts...)
+			})
+		},
+		Estimate: bogosortEstimate,
+	})
+	Register(Algorithm{
+		Name:          "miracle",
+		Title:         "Miraclesort",
+		Stable:        true,
+		Deterministic: true,
+		Complexity:    "O(∞)",
+		Sort: func(ctx context.Context, data Interface, opts ...Option) (int, error) {
+			return data.Len(), miraclesortInterface(ctx, data, newOptions(opts))
+		},
+		Estimate: miraclesortEstimate,
+	})
+	Register(Algorithm{
+		Name:          "slow",
+		Title:         "Slowsort",
+		Deterministic: true,
+		Complexity:    "O(n^(log n / (2+ε)))",
+		Sort: func(ctx context.Context, data Interface, opts ...Option) (int, error) {
+			return sortIndices(data, func(p []int, cmp func(a, b int) int) error {
+				return SlowsortWith(ctx, p, cmp, opts...)
+			})
+		},
+		Estimate: slowsortEstimate,
+	})
+	Register(Algorithm{
+		Name:          "stalin",
+		Title:         "Stalinsort",
+		Filtering:     true,
+		Stable:        true,
+		Deterministic: true,
+		Complexity:    "O(n)",
+		Sort: func(ctx context.Context, data Interface, opts ...Option) (int, error) {
+			p := identity(data.Len())
+			kept, err := StalinsortWith(ctx, p, data.Compare, opts...)
+			if err != nil {
+				return 0, err
+			}
+			permute(data, kept)
+
+			return len(kept), nil
+		},
+		Estimate: stalinsortEstimate,
+	})
+}
+
+// sortIndices sorts data by sorting indices of its elements with the sort
+// function and then moving elements to their positions. Elements are moved
+// even if sort returns an error, so data reflects the progress of sorting.
+func sortIndices(data Interface, sort func(p []int, cmp func(a, b int) int) error) (int, error) {
+	p := identity(data.Len())
+	err := sort(p, data.Compare)
+	permute(data, p)
+
+	return data.Len(), err
+}
+
+// identity returns indices from 0 to n-1 in ascending order.
+func identity(n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+
+	return p
+}
+
+// permute moves the element with original index p[k] to position k of data
+// for each k in p.
+func permute(data Interface, p []int) {
+	n := data.Len()
+	pos := identity(n) // pos[i] is the current position of the element with original index i
+	at := identity(n)  // at[k] is the original index of the element at position k
+	for k, i := range p {
+		j := pos[i]
+		if j == k {
+			continue
+		}
+		data.Swap(k, j)
+		pos[at[k]], pos[i] = j, k
+		at[j], at[k] = at[k], i
+	}
+}
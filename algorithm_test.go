@@ -0,0 +1,135 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlgorithms(t *testing.T) {
+	var names []string
+	for _, a := range Algorithms() {
+		names = append(names, a.Name)
+	}
+	if want := []string{"bogo", "miracle", "slow", "stalin"}; !slices.Equal(names, want) {
+		t.Errorf("Algorithms() returns %v, want %v", names, want)
+	}
+}
+
+func TestAlgorithmSort(t *testing.T) {
+	ctx := context.Background()
+	testcases := [][]int{
+		{},
+		{1},
+		{2, 1},
+		{3, 1, 2, 1},
+		{5, 4, 3, 2, 1},
+	}
+	for _, name := range []string{"bogo", "slow"} {
+		a, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("Lookup(%q) cannot find algorithm", name)
+		}
+		for _, tc := range testcases {
+			a, tc := a, tc
+			t.Run(name+"/"+fmt.Sprint(tc), func(t *testing.T) {
+				t.Parallel()
+				want := slices.Clone(tc)
+				slices.Sort(want)
+
+				got, err := SortFunc(ctx, a, slices.Clone(tc), cmp.Compare[int])
+				if err != nil {
+					t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) returns error: %v", ctx, a, tc, err)
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) cannot sort; got %v, want %v", ctx, a, tc, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestAlgorithmSortFiltering(t *testing.T) {
+	ctx := context.Background()
+	a, _ := Lookup("stalin")
+	tc := []int{3, 1, 4, 1, 5, 9, 2, 6}
+
+	got, err := SortFunc(ctx, a, slices.Clone(tc), cmp.Compare[int])
+	if err != nil {
+		t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) returns error: %v", ctx, a, tc, err)
+	}
+	if want := []int{3, 4, 5, 9}; !slices.Equal(got, want) {
+		t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) = %v, want %v", ctx, a, tc, got, want)
+	}
+}
+
+func TestAlgorithmSortStable(t *testing.T) {
+	ctx := context.Background()
+	cmpFirstLetter := func(a, b string) int { return cmp.Compare(a[0], b[0]) }
+	tc := []string{"b1", "a1", "b2", "a2", "a3"}
+	want := []string{"a1", "a2", "a3", "b1", "b2"}
+	for _, name := range []string{"bogo", "slow"} {
+		a, _ := Lookup(name)
+
+		got, err := SortFunc(ctx, a, slices.Clone(tc), cmpFirstLetter, WithStable())
+		if err != nil {
+			t.Errorf("SortFunc(%v, %v, %v, cmpFirstLetter, WithStable()) returns error: %v", ctx, a, tc, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("SortFunc(%v, %v, %v, cmpFirstLetter, WithStable()) cannot sort; got %v, want %v", ctx, a, tc, got, want)
+		}
+	}
+}
+
+func TestAlgorithmSortMiracle(t *testing.T) {
+	ctx := context.Background()
+	a, _ := Lookup("miracle")
+	tc := []int{3, 1, 2}
+	x := slices.Clone(tc)
+	clock := newFakeClock()
+
+	done := make(chan error)
+	go func() {
+		_, err := SortFunc(ctx, a, x, cmp.Compare[int], WithClock(clock))
+		done <- err
+	}()
+	<-clock.waiting
+	slices.Sort(x) // miracle
+	clock.ticks <- time.Time{}
+
+	if err := <-done; err != nil {
+		t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) returns error: %v", ctx, a, tc, err)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(x, want) {
+		t.Errorf("SortFunc(%v, %v, %v, cmp.Compare) cannot sort; got %v, want %v", ctx, a, tc, x, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	sortNothing := func(ctx context.Context, data Interface, opts ...Option) (int, error) {
+		return data.Len(), nil
+	}
+	testcases := map[string]Algorithm{
+		"empty name": {Sort: sortNothing},
+		"nil Sort":   {Name: "nil-sort"},
+		"duplicate":  {Name: "bogo", Sort: sortNothing},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil || !strings.HasPrefix(fmt.Sprint(r), "sortof: ") {
+					t.Errorf("Register(%v) panics with %v, want sortof error", tc, r)
+				}
+			}()
+			Register(tc)
+		})
+	}
+	if _, ok := Lookup("nil-sort"); ok {
+		t.Errorf("Lookup(%q) finds algorithm which cannot be registered", "nil-sort")
+	}
+}
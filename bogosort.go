@@ -4,8 +4,11 @@ package sortof
 import (
 	"cmp"
 	"context"
-	"math/rand"
 	"slices"
+	"sync"
+	"time"
+
+	"github.com/macie/sortof/metrics"
 )
 
 // Bogosort sorts the slice x of any ordered type in ascending order. A context
@@ -23,20 +26,255 @@ func Bogosort[S ~[]E, E cmp.Ordered](ctx context.Context, x S) error {
 // return a negative number when a < b, a positive number when a > b and zero
 // when a == b.
 //
+// The sort is not guaranteed to be stable. Use BogosortStableFunc to keep
+// the original order of equal elements.
+//
 // See https://en.wikipedia.org/wiki/Bogosort.
 func BogosortFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
-	n := len(x)
+	return BogosortWith(ctx, x, cmp)
+}
+
+// BogosortWith sorts the slice x of any type in ascending order as
+// determined by the cmp function with settings from opts. Applicable options
+// are WithShuffler, WithSeed, WithLockedPrefix, WithStable, WithWorkers,
+// WithBestEffort, WithFallback, WithStats, WithObserver, WithBudget and
+// WithComparatorCheck. A context controls cancellation, because the worst-case
+// time complexity is O(infinity).
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortWith[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, opts ...Option) error {
+	o := newOptions(opts)
+	defer o.stats.since(time.Now())
+	ctx, cmp, o, cancel := prepare(ctx, cmp, o)
+	defer cancel()
+
+	if o.stable {
+		return stableFunc(x, cmp, func(y []indexed[E], cmp func(a, b indexed[E]) int) error {
+			return bogosortUnstable(ctx, y, cmp, o)
+		})
+	}
+
+	return bogosortUnstable(ctx, x, cmp, o)
+}
+
+// BogosortWithRand sorts the slice x of any type in ascending order as
+// determined by the cmp function. Permutations of x are generated by
+// the shuffler r, so a run with seeded random source can be reproduced.
+// A context controls cancellation, because the worst-case time complexity is
+// O(infinity).
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortWithRand[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, r Shuffler) error {
+	return BogosortWith(ctx, x, cmp, WithShuffler(r))
+}
+
+// BogosortParallelFunc sorts the slice x of any type in ascending order as
+// determined by the cmp function. Permutations are searched concurrently by
+// workers goroutines. Each of them shuffles its own copy of x with its own
+// random source. The first worker which finds a sorted permutation writes it
+// back to x and cancels the others. When workers <= 0,
+// runtime.GOMAXPROCS(0) goroutines are used. Function cmp must be safe for
+// concurrent use.
+//
+// A context controls cancellation, because the worst-case time complexity is
+// O(infinity). Cancelled context leaves x unchanged.
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortParallelFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, workers int) error {
+	return bogosortParallel(ctx, x, cmp, newOptions([]Option{WithWorkers(workers)}))
+}
+
+// BogosortParallelWithRand works like BogosortParallelFunc, but the random
+// source of each worker (numbered from 0) is created by the newShuffler
+// function.
+func BogosortParallelWithRand[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, workers int, newShuffler func(worker int) Shuffler) error {
+	o := newOptions([]Option{WithWorkers(workers)})
+	o.newShuffler = newShuffler
+
+	return bogosortParallel(ctx, x, cmp, o)
+}
+
+// BogosortStableFunc sorts the slice x of any type in ascending order as
+// determined by the cmp function while keeping the original order of equal
+// elements. A permutation is accepted only if it is sorted and equal elements
+// are in their original order. A context controls cancellation, because
+// the worst-case time complexity is O(infinity).
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortStableFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
+	return BogosortWith(ctx, x, cmp, WithStable())
+}
+
+// BogosortStableWithRand works like BogosortStableFunc, but permutations of x
+// are generated by the shuffler r.
+func BogosortStableWithRand[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, r Shuffler) error {
+	return BogosortWith(ctx, x, cmp, WithStable(), WithShuffler(r))
+}
+
+// BogosortLockedPrefix sorts the slice x of any ordered type in ascending
+// order. It is optimized variant of Bogosort, which never shuffles again
+// elements already placed in their final positions at the beginning of x.
+// A context controls cancellation, because the worst-case time complexity is
+// O(infinity). When sorting floating-point numbers, NaNs are ordered before
+// other values.
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortLockedPrefix[S ~[]E, E cmp.Ordered](ctx context.Context, x S) error {
+	return BogosortLockedPrefixFunc(ctx, x, cmp.Compare)
+}
+
+// BogosortLockedPrefixFunc sorts the slice x of any type in ascending order
+// as determined by the cmp function. After each shuffle it locks the longest
+// prefix of x which elements are not greater than any element after them,
+// and shuffles only the remaining suffix. A context controls cancellation,
+// because the worst-case time complexity is O(infinity).
+//
+// See https://en.wikipedia.org/wiki/Bogosort.
+func BogosortLockedPrefixFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
+	return BogosortWith(ctx, x, cmp, WithLockedPrefix())
+}
+
+// BogosortLockedPrefixWithRand works like BogosortLockedPrefixFunc, but
+// permutations of x are generated by the shuffler r.
+func BogosortLockedPrefixWithRand[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, r Shuffler) error {
+	return BogosortWith(ctx, x, cmp, WithLockedPrefix(), WithShuffler(r))
+}
+
+// bogosortUnstable sorts x with settings from o, except stability.
+func bogosortUnstable[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options) error {
+	return withFallback(ctx, x, cmp, o, func(ctx context.Context, o options) error {
+		var err error
+		if o.workers > 1 {
+			err = bogosortParallel(ctx, x, cmp, o)
+		} else {
+			err = bogosortSequential(ctx, x, cmp, o, o.shuffler(0))
+		}
+		if err != nil && o.bestEffort {
+			return newPartialSortError(err, x, cmp)
+		}
+
+		return err
+	})
+}
+
+// bogosortSequential shuffles x with r until it is sorted. With o.lockPrefix
+// elements already placed in their final positions at the beginning of x are
+// never shuffled again. With o.bestEffort the permutation with the least
+// number of inversions is restored when ctx is cancelled.
+func bogosortSequential[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options, r Shuffler) error {
+	compare := observedCompare(x, cmp, o.observers)
+	var best S
+	bestInversions := 0
+	locked := 0
+	for iteration := 1; ; iteration++ {
+		if o.lockPrefix {
+			locked += lockedPrefix(locked, len(x), compare)
+			if locked >= len(x)-1 {
+				break
+			}
+		} else if isSorted(len(x), compare) {
+			break
+		}
+
+		if o.bestEffort {
+			if n := metrics.Inversions(x, cmp); best == nil || n < bestInversions {
+				best, bestInversions = append(best[:0], x...), n
+			}
+		}
 
-	for !slices.IsSortedFunc(x, cmp) {
 		select {
 		case <-ctx.Done():
+			if best != nil {
+				copy(x, best)
+			}
 			return context.Cause(ctx)
 		default:
-			rand.Shuffle(n, func(i, j int) {
+			r.Shuffle(len(x)-locked, func(i, j int) {
+				i, j = locked+i, locked+j
 				x[i], x[j] = x[j], x[i]
+				o.observers.OnSwap(i, j)
+			})
+			o.observers.OnShuffle(iteration)
+		}
+	}
+
+	return inconsistency(ctx)
+}
+
+// bogosortParallel searches permutations of x concurrently by o.workers
+// goroutines. Each of them shuffles its own copy of x, and the first one
+// which finds a sorted permutation writes it back to x. With o.bestEffort
+// the best permutation of all workers is written back when ctx is cancelled.
+func bogosortParallel[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options) error {
+	workersCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// copies are made before workers start writing back to x
+	copies := make([]S, o.workers)
+	for w := range copies {
+		copies[w] = slices.Clone(x)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		winner sync.Once
+		found  bool
+	)
+	for w := range copies {
+		wg.Add(1)
+		go func(y S, r Shuffler) {
+			defer wg.Done()
+			if err := bogosortSequential(workersCtx, y, cmp, o, r); err != nil {
+				return
+			}
+			winner.Do(func() {
+				copy(x, y)
+				found = true
+				cancel()
 			})
+		}(copies[w], o.shuffler(w))
+	}
+	wg.Wait()
+
+	if !found {
+		if o.bestEffort {
+			best := slices.MinFunc(copies, func(a, b S) int {
+				return metrics.Inversions(a, cmp) - metrics.Inversions(b, cmp)
+			})
+			copy(x, best)
 		}
+		return context.Cause(ctx)
+	}
+
+	return inconsistency(ctx)
+}
+
+// lockedPrefix returns length of the longest prefix of elements with indices
+// from first to n-1 which are in their final positions, so each of them is
+// not greater than any element after it. Elements are compared by
+// the compare function of their indices.
+func lockedPrefix(first, n int, compare func(i, j int) int) int {
+	if first >= n {
+		return 0
+	}
+
+	// minAfter[i-first] is an index of the smallest element from i to n-1
+	minAfter := make([]int, n-first)
+	minAfter[n-first-1] = n - 1
+	for i := n - 2; i >= first; i-- {
+		minAfter[i-first] = minAfter[i-first+1]
+		if compare(i, minAfter[i-first]) <= 0 {
+			minAfter[i-first] = i
+		}
+	}
+
+	k := first
+	for k < n-1 && compare(k, minAfter[k-first+1]) <= 0 {
+		k++
+	}
+	if k == n-1 {
+		k++
 	}
 
-	return nil
+	return k - first
 }
@@ -3,10 +3,14 @@ package sortof
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"slices"
 	"testing"
+	"time"
+
+	"github.com/macie/sortof/metrics"
 )
 
 func TestBogosortFloat(t *testing.T) {
@@ -168,3 +172,324 @@ func TestBogosortFuncString(t *testing.T) {
 		})
 	}
 }
+
+// countingShuffler counts calls of the wrapped Shuffler.
+type countingShuffler struct {
+	Shuffler
+	calls int
+}
+
+func (s *countingShuffler) Shuffle(n int, swap func(i, j int)) {
+	s.calls++
+	s.Shuffler.Shuffle(n, swap)
+}
+
+func TestBogosortWithRandSeeded(t *testing.T) {
+	ctx := context.Background()
+	testcases := [][]int{
+		{1, 2, 3},
+		{5, 4, 3, 2, 1},
+		{math.MaxInt, 2, 0, -1, math.MinInt},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+
+			first := &countingShuffler{Shuffler: NewSeededShuffler(42)}
+			if err := BogosortWithRand(ctx, slices.Clone(tc), cmp.Compare[int], first); err != nil {
+				t.Fatalf("BogosortWithRand(%v, %v, cmp.Compare, seed=42) returns error: %v", ctx, tc, err)
+			}
+
+			second := &countingShuffler{Shuffler: NewSeededShuffler(42)}
+			collection := slices.Clone(tc)
+			if err := BogosortWithRand(ctx, collection, cmp.Compare[int], second); err != nil {
+				t.Fatalf("BogosortWithRand(%v, %v, cmp.Compare, seed=42) returns error: %v", ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				want := slices.Clone(tc)
+				slices.Sort(want)
+				t.Errorf("BogosortWithRand(%v, %v, cmp.Compare, seed=42) cannot sort; got %v, want %v", ctx, tc, collection, want)
+			}
+			if first.calls != second.calls {
+				t.Errorf("BogosortWithRand(%v, %v, cmp.Compare, seed=42) is not reproducible; got %d shuffles, want %d", ctx, tc, second.calls, first.calls)
+			}
+		})
+	}
+}
+
+func TestBogosortWithRandCrypto(t *testing.T) {
+	ctx := context.Background()
+	testcases := [][]string{
+		{"1", "2", "3"},
+		{"100", "2", "0", "-1"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+
+			collection := slices.Clone(tc)
+
+			err := BogosortWithRand(ctx, collection, cmp.Compare[string], NewCryptoShuffler())
+			if err != nil {
+				t.Errorf("BogosortWithRand(%v, %v, cmp.Compare, crypto) returns error: %v", ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				want := slices.Clone(tc)
+				slices.Sort(want)
+				t.Errorf("BogosortWithRand(%v, %v, cmp.Compare, crypto) cannot sort; got %v, want %v", ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestBogosortLockedPrefixFloat(t *testing.T) {
+	ctx := context.Background()
+	testcases := [][]float64{
+		{1, 2, 3},
+		{math.MaxFloat64, 2, 0, -1, math.SmallestNonzeroFloat64, math.Log(-1)},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+
+			collection := slices.Clone(tc)
+
+			err := BogosortLockedPrefix(ctx, collection)
+			if err != nil {
+				t.Errorf("BogosortLockedPrefix(%v, %v) returns error: %v", ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				want := slices.Clone(tc)
+				slices.Sort(want)
+				t.Errorf("BogosortLockedPrefix(%v, %v) cannot sort; got %v, want %v", ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestBogosortLockedPrefixFuncString(t *testing.T) {
+	ctx := context.Background()
+	cmpStrings := func(a, b string) int { return cmp.Compare(a, b) }
+	testcases := [][]string{
+		{},
+		{"a"},
+		{"1", "2", "3"},
+		{"100", "2", "0", "-1"},
+		{"a", "", "b", "", "a"},
+		{"l", "k", "j", "i", "h", "g", "f", "e", "d", "c", "b", "a"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+
+			err := BogosortLockedPrefixFunc(ctx, collection, cmpStrings)
+			if err != nil {
+				t.Errorf("BogosortLockedPrefixFunc(%v, %v, cmpStrings) returns error: %v", ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				want := slices.Clone(tc)
+				slices.Sort(want)
+				t.Errorf("BogosortLockedPrefixFunc(%v, %v, cmpStrings) cannot sort; got %v, want %v", ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestBogosortLockedPrefixCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tc := []int{3, 2, 1}
+
+	err := BogosortLockedPrefix(ctx, slices.Clone(tc))
+	if err != context.Canceled {
+		t.Errorf("BogosortLockedPrefix(%v, %v) returns error: %v, want %v", ctx, tc, err, context.Canceled)
+	}
+}
+
+func BenchmarkBogosort(b *testing.B) {
+	ctx := context.Background()
+	for _, n := range []int{4, 6, 8} {
+		input := reversedInts(n)
+		b.Run(fmt.Sprintf("Bogosort/n=%d", n), func(b *testing.B) {
+			r := NewSeededShuffler(1)
+			for i := 0; i < b.N; i++ {
+				BogosortWithRand(ctx, slices.Clone(input), cmp.Compare[int], r)
+			}
+		})
+		b.Run(fmt.Sprintf("BogosortLockedPrefix/n=%d", n), func(b *testing.B) {
+			r := NewSeededShuffler(1)
+			for i := 0; i < b.N; i++ {
+				BogosortLockedPrefixWithRand(ctx, slices.Clone(input), cmp.Compare[int], r)
+			}
+		})
+	}
+	for _, n := range []int{12, 15} {
+		input := reversedInts(n)
+		b.Run(fmt.Sprintf("BogosortLockedPrefix/n=%d", n), func(b *testing.B) {
+			r := NewSeededShuffler(1)
+			for i := 0; i < b.N; i++ {
+				BogosortLockedPrefixWithRand(ctx, slices.Clone(input), cmp.Compare[int], r)
+			}
+		})
+	}
+}
+
+// reversedInts returns slice of n integers in descending order.
+func reversedInts(n int) []int {
+	x := make([]int, n)
+	for i := range x {
+		x[i] = n - i
+	}
+	return x
+}
+
+func TestBogosortStableFunc(t *testing.T) {
+	ctx := context.Background()
+	type record struct {
+		key  int
+		name string
+	}
+	cmpKeys := func(a, b record) int { return cmp.Compare(a.key, b.key) }
+	testcases := [][]record{
+		{{1, "a"}, {1, "b"}, {1, "c"}},
+		{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}, {0, "e"}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+			want := slices.Clone(tc)
+			slices.SortStableFunc(want, cmpKeys)
+
+			err := BogosortStableFunc(ctx, collection, cmpKeys)
+			if err != nil {
+				t.Errorf("BogosortStableFunc(%v, %v, cmpKeys) returns error: %v", ctx, tc, err)
+			}
+			if !slices.Equal(collection, want) {
+				t.Errorf("BogosortStableFunc(%v, %v, cmpKeys) is not stable; got %v, want %v", ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestBogosortWith(t *testing.T) {
+	ctx := context.Background()
+	type record struct {
+		key  int
+		name string
+	}
+	cmpKeys := func(a, b record) int { return cmp.Compare(a.key, b.key) }
+	tc := []record{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}, {0, "e"}}
+	want := slices.Clone(tc)
+	slices.SortStableFunc(want, cmpKeys)
+	testcases := map[string][]Option{
+		"seed":           {WithSeed(42)},
+		"stable":         {WithStable(), WithLockedPrefix()},
+		"stable workers": {WithStable(), WithWorkers(4), WithSeed(1)},
+	}
+	for name, opts := range testcases {
+		name, opts := name, opts
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+
+			if err := BogosortWith(ctx, collection, cmpKeys, opts...); err != nil {
+				t.Errorf("BogosortWith(%v, %v, cmpKeys, %s options) returns error: %v", ctx, tc, name, err)
+			}
+			if !slices.IsSortedFunc(collection, cmpKeys) {
+				t.Errorf("BogosortWith(%v, %v, cmpKeys, %s options) cannot sort; got %v", ctx, tc, name, collection)
+			}
+			if len(opts) > 1 && !slices.Equal(collection, want) {
+				t.Errorf("BogosortWith(%v, %v, cmpKeys, %s options) is not stable; got %v, want %v", ctx, tc, name, collection, want)
+			}
+		})
+	}
+}
+
+// inversionsObserver tracks the least number of inversions of x after
+// shuffles.
+type inversionsObserver struct {
+	NopObserver
+	x   []int
+	min int
+}
+
+func (o *inversionsObserver) OnShuffle(int) {
+	o.min = min(o.min, metrics.Inversions(o.x, cmp.Compare[int]))
+}
+
+func TestBogosortWithBestEffort(t *testing.T) {
+	ctx := context.Background()
+	tc := reversedInts(8)
+	for _, workers := range []int{1, 3} {
+		collection := slices.Clone(tc)
+		obs := &inversionsObserver{x: collection, min: metrics.Inversions(tc, cmp.Compare[int])}
+		opts := []Option{WithSeed(1), WithBestEffort(), WithBudget(Budget{Shuffles: 50}), WithWorkers(workers)}
+		if workers == 1 {
+			opts = append(opts, WithObserver(obs))
+		}
+
+		err := BogosortWith(ctx, collection, cmp.Compare[int], opts...)
+		var partial *PartialSortError
+		if !errors.As(err, &partial) || !errors.Is(err, ErrBudgetExhausted) {
+			t.Fatalf("BogosortWith(%v, %v, cmp.Compare, WithBestEffort) with %d workers returns error: %v, want *PartialSortError", ctx, tc, workers, err)
+		}
+		if got := metrics.Inversions(collection, cmp.Compare[int]); got != partial.Inversions || got >= metrics.Inversions(tc, cmp.Compare[int]) {
+			t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithBestEffort) with %d workers leaves %v with %d inversions, error reports %d", ctx, tc, workers, collection, got, partial.Inversions)
+		}
+		if workers == 1 && partial.Inversions != obs.min {
+			t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithBestEffort) leaves %d inversions, want the least seen %d", ctx, tc, partial.Inversions, obs.min)
+		}
+	}
+}
+
+func TestBogosortParallelFunc(t *testing.T) {
+	ctx := context.Background()
+	cmpInts := func(a, b int) int { return cmp.Compare(a, b) }
+	testcases := [][]int{
+		{},
+		{1, 2, 3},
+		{math.MaxInt, 2, 0, -1, math.MinInt},
+		{6, 5, 4, 3, 2, 1},
+	}
+	for _, tc := range testcases {
+		for _, workers := range []int{0, 1, 4} {
+			tc, workers := tc, workers
+			t.Run(fmt.Sprintf("%v/workers=%d", tc, workers), func(t *testing.T) {
+				t.Parallel()
+				collection := slices.Clone(tc)
+
+				err := BogosortParallelFunc(ctx, collection, cmpInts, workers)
+				if err != nil {
+					t.Errorf("BogosortParallelFunc(%v, %v, cmpInts, %d) returns error: %v", ctx, tc, workers, err)
+				}
+				if !slices.IsSorted(collection) {
+					want := slices.Clone(tc)
+					slices.Sort(want)
+					t.Errorf("BogosortParallelFunc(%v, %v, cmpInts, %d) cannot sort; got %v, want %v", ctx, tc, workers, collection, want)
+				}
+			})
+		}
+	}
+}
+
+func TestBogosortParallelFuncCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	tc := reversedInts(100)
+	collection := slices.Clone(tc)
+
+	err := BogosortParallelFunc(ctx, collection, cmp.Compare[int], 4)
+	if err != context.DeadlineExceeded {
+		t.Errorf("BogosortParallelFunc(%v, %v, cmp.Compare, 4) returns error: %v, want %v", ctx, tc, err, context.DeadlineExceeded)
+	}
+	if !slices.Equal(collection, tc) {
+		t.Errorf("BogosortParallelFunc(%v, %v, cmp.Compare, 4) modifies slice after cancellation; got %v", ctx, tc, collection)
+	}
+}
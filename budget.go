@@ -0,0 +1,75 @@
+package sortof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrBudgetExhausted is returned when sorting exceeds its budget.
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// Budget limits the work of sorting functions (see WithBudget). Unlike
+// a timeout, it does not depend on the speed of the machine, so sorting with
+// a deterministic algorithm (or a seeded Bogosort) always stops at the same
+// step. Zero or negative limit means no limit.
+type Budget struct {
+	Shuffles    int64 // random permutations of the slice (Bogosort)
+	Comparisons int64 // calls of the comparison function
+	Calls       int64 // recursive calls (Slowsort)
+	Sleeps      int64 // waits for a miracle (Miraclesort)
+}
+
+// BudgetError describes the exceeded limit of a Budget. It wraps
+// ErrBudgetExhausted.
+type BudgetError struct {
+	Resource string // name of the exceeded limit, e.g. "shuffles"
+	Limit    int64
+}
+
+// Error returns a description of the exceeded limit.
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s: more than %d %s", ErrBudgetExhausted, e.Limit, e.Resource)
+}
+
+// Unwrap returns ErrBudgetExhausted.
+func (e *BudgetError) Unwrap() error {
+	return ErrBudgetExhausted
+}
+
+// budgetObserver is an Observer which counts events and fails when a limit
+// of the budget is exceeded.
+type budgetObserver struct {
+	Stats
+	limit Budget
+	fail  context.CancelCauseFunc
+}
+
+func (b *budgetObserver) OnCompare(i, j, result int) {
+	b.Stats.OnCompare(i, j, result)
+	b.check("comparisons", &b.Stats.Comparisons, b.limit.Comparisons)
+}
+
+func (b *budgetObserver) OnShuffle(iteration int) {
+	b.Stats.OnShuffle(iteration)
+	b.check("shuffles", &b.Stats.Shuffles, b.limit.Shuffles)
+}
+
+func (b *budgetObserver) OnRecurse(i, j, depth int) {
+	b.Stats.OnRecurse(i, j, depth)
+	b.check("calls", &b.Stats.Calls, b.limit.Calls)
+}
+
+func (b *budgetObserver) OnSleep() {
+	b.Stats.OnSleep()
+	b.check("sleeps", &b.Stats.Sleeps, b.limit.Sleeps)
+}
+
+// check cancels sorting with a *BudgetError if the limit of the resource is
+// exceeded.
+func (b *budgetObserver) check(resource string, used *int64, limit int64) {
+	if limit > 0 && atomic.LoadInt64(used) > limit {
+		b.fail(&BudgetError{Resource: resource, Limit: limit})
+	}
+}
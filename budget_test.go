@@ -0,0 +1,101 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithBudget(t *testing.T) {
+	ctx := context.Background()
+	tc := reversedInts(10)
+	testcases := map[string]struct {
+		sort     func(x []int, b Budget) error
+		budget   Budget
+		resource string
+	}{
+		"BogosortWith/shuffles": {
+			sort: func(x []int, b Budget) error {
+				return BogosortWith(ctx, x, cmp.Compare[int], WithSeed(1), WithBudget(b))
+			},
+			budget:   Budget{Shuffles: 10},
+			resource: "shuffles",
+		},
+		"BogosortWith/comparisons": {
+			sort: func(x []int, b Budget) error {
+				return BogosortWith(ctx, x, cmp.Compare[int], WithWorkers(2), WithBudget(b))
+			},
+			budget:   Budget{Comparisons: 100},
+			resource: "comparisons",
+		},
+		"SlowsortWith/calls": {
+			sort: func(x []int, b Budget) error {
+				return SlowsortWith(ctx, x, cmp.Compare[int], WithBudget(b))
+			},
+			budget:   Budget{Calls: 20, Shuffles: 1},
+			resource: "calls",
+		},
+		"StalinsortWith/comparisons": {
+			sort: func(x []int, b Budget) error {
+				_, err := StalinsortWith(ctx, x, cmp.Compare[int], WithBudget(b))
+				return err
+			},
+			budget:   Budget{Comparisons: 5},
+			resource: "comparisons",
+		},
+		"MiraclesortWith/sleeps": {
+			sort: func(x []int, b Budget) error {
+				return MiraclesortWith(ctx, x, cmp.Compare[int], WithPollInterval(time.Nanosecond), WithBudget(b))
+			},
+			budget:   Budget{Sleeps: 3},
+			resource: "sleeps",
+		},
+	}
+	for name, sorter := range testcases {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := sorter.sort(slices.Clone(tc), sorter.budget)
+			if !errors.Is(err, ErrBudgetExhausted) {
+				t.Fatalf("%s(%v, %v, cmp.Compare, WithBudget(%+v)) returns error: %v, want %v", name, ctx, tc, sorter.budget, err, ErrBudgetExhausted)
+			}
+			var budgetErr *BudgetError
+			if !errors.As(err, &budgetErr) || budgetErr.Resource != sorter.resource {
+				t.Errorf("%s(%v, %v, cmp.Compare, WithBudget(%+v)) returns error: %v, want exceeded %s", name, ctx, tc, sorter.budget, err, sorter.resource)
+			}
+		})
+	}
+}
+
+func TestWithBudgetDeterministic(t *testing.T) {
+	ctx := context.Background()
+	tc := reversedInts(20)
+	var results [2][]int
+	for i := range results {
+		results[i] = slices.Clone(tc)
+		err := SlowsortWith(ctx, results[i], cmp.Compare[int], WithBudget(Budget{Comparisons: 100}))
+		if !errors.Is(err, ErrBudgetExhausted) {
+			t.Fatalf("SlowsortWith(%v, %v, cmp.Compare, WithBudget) returns error: %v, want %v", ctx, tc, err, ErrBudgetExhausted)
+		}
+	}
+	if !slices.Equal(results[0], results[1]) {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithBudget) stops at different steps; got %v and %v", ctx, tc, results[0], results[1])
+	}
+}
+
+func TestWithBudgetSufficient(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 2}
+	collection := slices.Clone(tc)
+
+	if err := SlowsortWith(ctx, collection, cmp.Compare[int], WithBudget(Budget{Comparisons: 1000, Calls: 1000})); err != nil {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithBudget) returns error: %v", ctx, tc, err)
+	}
+	if !slices.IsSorted(collection) {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithBudget) cannot sort; got %v", ctx, tc, collection)
+	}
+}
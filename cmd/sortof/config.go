@@ -5,41 +5,136 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"os"
 	"os/signal"
 	"reflect"
+	"strings"
 	"time"
+
+	"github.com/macie/sortof"
 )
 
-const helpMsg = "sortof - sort lines of text files\n" +
+// helpMsg is the usage description of the program.
+var helpMsg = "sortof - sort lines of text files\n" +
 	"\n" +
 	"Usage:\n" +
-	"   sortof <algorithm> [-t <timeout>] [FILE...]\n" +
+	"   sortof <algorithm> [-t <timeout>] [-k <field>] [-stable] [-seed <n>]\n" +
+	"                      [-lock-prefix] [-j <n>] [-cosmic-rate <r>] [-stats]\n" +
+	"                      [-max-shuffles <n>] [-max-comparisons <n>]\n" +
+	"                      [-best-effort] [-fallback-after <duration>]\n" +
+	"                      [-refuse-above <duration>] [FILE...]\n" +
+	"   sortof miracle [-t <timeout>] [-k <field>] -watch FILE\n" +
+	"   sortof estimate <algorithm> [-t <timeout>] [-k <field>] [-stable]\n" +
+	"                      [-seed <n>] [-lock-prefix] [-j <n>]\n" +
+	"                      [-fallback-after <duration>] [FILE...]\n" +
+	"   sortof simulate <algorithm> -n <n> [-trials <n>] [-csv] [-t <timeout>]\n" +
+	"                      [-seed <n>] [-stable] [-lock-prefix] [-j <n>]\n" +
+	"                      [-fallback-after <duration>]\n" +
+	"   sortof generate -adversary <algorithm> -n <n>\n" +
 	"   sortof [-h] [-v]\n" +
 	"\n" +
 	"Options:\n" +
 	"   -t <timeout>  timeout after which the program exits (default: 0).\n" +
 	"                 Valid time units: ns, us, ms, s, m, h\n" +
+	"   -k <field>    sort by the n-th whitespace-separated field instead of\n" +
+	"                 the whole line (default: 0 - whole line)\n" +
+	"   -stable       keep the original order of lines with equal keys\n" +
+	"   -seed <n>     seed of random number generator used by randomized\n" +
+	"                 algorithms and cosmic rays. The used seed is printed\n" +
+	"                 to standard error (default: 0 - random seed)\n" +
+	"   -lock-prefix  bogo: shuffle only elements after the longest prefix\n" +
+	"                 which is already in final position\n" +
+	"   -j <n>        bogo, slow: number of concurrent workers (default: 1)\n" +
+	"   -cosmic-rate <r>\n" +
+	"                 miracle: average number of simulated cosmic rays per\n" +
+	"                 second, which swap random lines (default: 0)\n" +
+	"   -watch FILE   miracle: re-read FILE from disk whenever it stops changing\n" +
+	"                 and exit when its lines are sorted; only -t and -k can\n" +
+	"                 be used with it\n" +
+	"   -max-shuffles <n>\n" +
+	"                 bogo: stop after n shuffles (default: 0 - no limit)\n" +
+	"   -max-comparisons <n>\n" +
+	"                 stop after n comparisons of lines (default: 0 - no limit)\n" +
+	"   -best-effort  bogo, slow: when sorting is interrupted, print the most\n" +
+	"                 sorted permutation of lines before exiting with error\n" +
+	"   -fallback-after <duration>\n" +
+	"                 bogo, miracle, slow: give up after the duration and sort\n" +
+	"                 lines with a real sorting algorithm (default: 0 - never)\n" +
+	"   -refuse-above <duration>\n" +
+	"                 exit with error instead of sorting when the expected time\n" +
+	"                 of sorting is longer than the duration (default: 0 - never);\n" +
+	"                 it cannot be used with -cosmic-rate\n" +
+	"   -n <n>        simulate, generate: number of elements of inputs\n" +
+	"   -trials <n>   simulate: number of sorted random inputs (default: 100)\n" +
+	"   -csv          simulate: print histogram as CSV and summary to standard\n" +
+	"                 error\n" +
+	"   -adversary <algorithm>\n" +
+	"                 generate: algorithm for which the input is pessimal\n" +
+	"   -stats        print statistics of sorting to standard error after\n" +
+	"                 each file\n" +
 	"   -h            show this help message and exit\n" +
 	"   -v            show version information and exit\n" +
 	"\n" +
+	"Commands:\n" +
+	"   estimate      print the expected work and time of sorting lines,\n" +
+	"                 calibrated on the current machine. Lines which cannot be\n" +
+	"                 sorted during calibration are assumed to be in random\n" +
+	"                 order\n" +
+	"   simulate      sort random inputs many times and print the summary and\n" +
+	"                 histogram of the work of sorting\n" +
+	"   generate      print pessimal input of numbers from 0 to n-1, which\n" +
+	"                 makes the algorithm do the most work, one per line\n" +
+	"\n" +
 	"Algorithms:\n" +
-	"   bogo          Bogosort\n" +
-	"   miracle       Miraclesort\n" +
-	"   slow          Slowsort\n" +
-	"   stalin        Stalinsort\n" +
+	algorithmsHelp() +
 	"\n" +
 	"With no FILE, or when FILE is -, the command reads from standard input"
 
+// algorithmsHelp returns the list of registered algorithms for the usage
+// description.
+func algorithmsHelp() string {
+	var b strings.Builder
+	for _, a := range sortof.Algorithms() {
+		fmt.Fprintf(&b, "   %-13s %s\n", a.Name, a.Title)
+	}
+
+	return b.String()
+}
+
+// watchInterval is the interval between checks of the watched file.
+const watchInterval = 200 * time.Millisecond
+
+// defaultTrials is the default number of trials of the simulate command.
+const defaultTrials = 100
+
 // AppVersion is the version of the program.
 var AppVersion = "local-dev"
 
 // AppConfig contains configuration options for the program provided by the user.
 type AppConfig struct {
-	SortFunc    func(ctx context.Context, file io.ReadCloser) ([]string, error)
-	Files       []string
-	Timeout     time.Duration
-	ExitMessage string
+	Command        string // empty for sorting
+	Algorithm      sortof.Algorithm
+	Files          []string
+	Timeout        time.Duration
+	Key            int
+	Stable         bool
+	Seed           int64
+	LockPrefix     bool
+	Jobs           int
+	CosmicRate     float64
+	Watch          string
+	Stats          bool
+	MaxShuffles    int64
+	MaxComparisons int64
+	BestEffort     bool
+	FallbackAfter  time.Duration
+	RefuseAbove    time.Duration
+	N              int
+	Trials         int
+	CSV            bool
+	ExitMessage    string
 }
 
 // NewAppConfig creates a new AppConfig from the given command line arguments.
@@ -67,24 +162,49 @@ func NewAppConfig(cliArgs []string) (AppConfig, error) {
 		return config, nil
 	}
 
-	// subcommand
+	// command
 	switch cliArgs[0] {
-	case "bogo":
-		config.SortFunc = BogosortFile
-	case "miracle":
-		config.SortFunc = MiraclesortFile
-	case "slow":
-		config.SortFunc = SlowsortFile
-	case "stalin":
-		config.SortFunc = StalinsortFile
-	default:
-		return config, fmt.Errorf("'%s' is not an algorithm. See 'sortof -h' for help", cliArgs[0])
+	case "estimate", "simulate":
+		config.Command = cliArgs[0]
+		cliArgs = cliArgs[1:]
+		if len(cliArgs) == 0 {
+			return AppConfig{}, fmt.Errorf("missing algorithm for command '%s'. See 'sortof -h' for help", config.Command)
+		}
+	case "generate":
+		// algorithm is given by the -adversary flag
+		config.Command = cliArgs[0]
+	}
+
+	// subcommand
+	if config.Command != "generate" {
+		algorithm, ok := sortof.Lookup(cliArgs[0])
+		if !ok {
+			return config, fmt.Errorf("'%s' is not an algorithm. See 'sortof -h' for help", cliArgs[0])
+		}
+		config.Algorithm = algorithm
 	}
 
 	// subcommand options
 	s := flag.NewFlagSet("subcommand args", flag.ContinueOnError)
 	s.SetOutput(io.Discard)
 	s.DurationVar(&config.Timeout, "t", 0, "")
+	s.IntVar(&config.Key, "k", 0, "")
+	s.BoolVar(&config.Stable, "stable", false, "")
+	s.Int64Var(&config.Seed, "seed", 0, "")
+	s.BoolVar(&config.LockPrefix, "lock-prefix", false, "")
+	s.IntVar(&config.Jobs, "j", 0, "")
+	s.Float64Var(&config.CosmicRate, "cosmic-rate", 0, "")
+	s.StringVar(&config.Watch, "watch", "", "")
+	s.BoolVar(&config.Stats, "stats", false, "")
+	s.Int64Var(&config.MaxShuffles, "max-shuffles", 0, "")
+	s.Int64Var(&config.MaxComparisons, "max-comparisons", 0, "")
+	s.BoolVar(&config.BestEffort, "best-effort", false, "")
+	s.DurationVar(&config.FallbackAfter, "fallback-after", 0, "")
+	s.DurationVar(&config.RefuseAbove, "refuse-above", 0, "")
+	s.IntVar(&config.N, "n", 0, "")
+	s.IntVar(&config.Trials, "trials", 0, "")
+	s.BoolVar(&config.CSV, "csv", false, "")
+	adversary := s.String("adversary", "", "")
 	showSubcommandHelp := s.Bool("h", false, "")
 	if err := s.Parse(cliArgs[1:]); err != nil { // omit subcommand
 		return AppConfig{}, fmt.Errorf("%s. See 'sortof -h' for help", err)
@@ -93,6 +213,87 @@ func NewAppConfig(cliArgs []string) (AppConfig, error) {
 		config.ExitMessage = helpMsg
 		return config, nil
 	}
+	if config.Key < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -k: field number cannot be negative. See 'sortof -h' for help", config.Key)
+	}
+	if config.Jobs < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -j: number of workers cannot be negative. See 'sortof -h' for help", config.Jobs)
+	}
+	if config.MaxShuffles < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -max-shuffles: limit cannot be negative. See 'sortof -h' for help", config.MaxShuffles)
+	}
+	if config.MaxComparisons < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -max-comparisons: limit cannot be negative. See 'sortof -h' for help", config.MaxComparisons)
+	}
+	if config.FallbackAfter < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%v\" for flag -fallback-after: duration cannot be negative. See 'sortof -h' for help", config.FallbackAfter)
+	}
+	if config.RefuseAbove < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%v\" for flag -refuse-above: duration cannot be negative. See 'sortof -h' for help", config.RefuseAbove)
+	}
+	if config.Command == "generate" {
+		if *adversary == "" {
+			return AppConfig{}, fmt.Errorf("missing flag -adversary for command 'generate'. See 'sortof -h' for help")
+		}
+		algorithm, ok := sortof.Lookup(*adversary)
+		if !ok {
+			return AppConfig{}, fmt.Errorf("'%s' is not an algorithm. See 'sortof -h' for help", *adversary)
+		}
+		config.Algorithm = algorithm
+	} else if *adversary != "" {
+		return AppConfig{}, fmt.Errorf("flag -adversary can be used only with command 'generate'. See 'sortof -h' for help")
+	}
+	if config.Command == "simulate" || config.Command == "generate" {
+		if config.N <= 0 {
+			return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -n: number of elements must be positive. See 'sortof -h' for help", config.N)
+		}
+		if len(s.Args()) > 0 {
+			return AppConfig{}, fmt.Errorf("command '%s' cannot be used with FILE arguments. See 'sortof -h' for help", config.Command)
+		}
+	} else if config.N != 0 {
+		return AppConfig{}, fmt.Errorf("flag -n can be used only with commands 'simulate' and 'generate'. See 'sortof -h' for help")
+	}
+	if config.Command == "simulate" {
+		if config.Trials < 0 {
+			return AppConfig{}, fmt.Errorf("invalid value \"%d\" for flag -trials: number of trials cannot be negative. See 'sortof -h' for help", config.Trials)
+		}
+		if config.Trials == 0 {
+			config.Trials = defaultTrials
+		}
+	} else if config.Trials != 0 || config.CSV {
+		return AppConfig{}, fmt.Errorf("flags -trials and -csv can be used only with command 'simulate'. See 'sortof -h' for help")
+	}
+	if config.CosmicRate < 0 {
+		return AppConfig{}, fmt.Errorf("invalid value \"%v\" for flag -cosmic-rate: rate cannot be negative. See 'sortof -h' for help", config.CosmicRate)
+	}
+	if config.CosmicRate > 0 && config.Algorithm.Name != "miracle" {
+		return AppConfig{}, fmt.Errorf("flag -cosmic-rate can be used only with miracle algorithm. See 'sortof -h' for help")
+	}
+	if config.CosmicRate > 0 && config.RefuseAbove > 0 {
+		// the sample run of the estimation is not hit by cosmic rays
+		return AppConfig{}, fmt.Errorf("flag -refuse-above cannot be used with flag -cosmic-rate. See 'sortof -h' for help")
+	}
+	if config.Watch != "" {
+		if config.Algorithm.Name != "miracle" {
+			return AppConfig{}, fmt.Errorf("flag -watch can be used only with miracle algorithm. See 'sortof -h' for help")
+		}
+		if len(s.Args()) > 0 {
+			return AppConfig{}, fmt.Errorf("flag -watch cannot be used with FILE arguments. See 'sortof -h' for help")
+		}
+		if config.Command != "" {
+			return AppConfig{}, fmt.Errorf("flag -watch cannot be used with command '%s'. See 'sortof -h' for help", config.Command)
+		}
+		// watching only reads the file, so other flags would be silently ignored
+		var ignored string
+		s.Visit(func(f *flag.Flag) {
+			if ignored == "" && f.Name != "watch" && f.Name != "t" && f.Name != "k" {
+				ignored = f.Name
+			}
+		})
+		if ignored != "" {
+			return AppConfig{}, fmt.Errorf("flag -%s cannot be used with flag -watch. See 'sortof -h' for help", ignored)
+		}
+	}
 
 	// files
 	if len(s.Args()) > 0 {
@@ -104,12 +305,51 @@ func NewAppConfig(cliArgs []string) (AppConfig, error) {
 
 // Equal reports whether two AppConfigs are equal. It is used in tests.
 func (c AppConfig) Equal(other AppConfig) bool {
-	return reflect.ValueOf(c.SortFunc).Pointer() == reflect.ValueOf(other.SortFunc).Pointer() &&
+	return c.Command == other.Command &&
+		c.Algorithm.Name == other.Algorithm.Name &&
 		reflect.DeepEqual(c.Files, other.Files) &&
 		c.Timeout == other.Timeout &&
+		c.Key == other.Key &&
+		c.Stable == other.Stable &&
+		c.Seed == other.Seed &&
+		c.LockPrefix == other.LockPrefix &&
+		c.Jobs == other.Jobs &&
+		c.CosmicRate == other.CosmicRate &&
+		c.Watch == other.Watch &&
+		c.Stats == other.Stats &&
+		c.MaxShuffles == other.MaxShuffles &&
+		c.MaxComparisons == other.MaxComparisons &&
+		c.BestEffort == other.BestEffort &&
+		c.FallbackAfter == other.FallbackAfter &&
+		c.RefuseAbove == other.RefuseAbove &&
+		c.N == other.N &&
+		c.Trials == other.Trials &&
+		c.CSV == other.CSV &&
 		c.ExitMessage == other.ExitMessage
 }
 
+// Compare compares lines a and b by the configured key field. It returns
+// a negative number when a < b, a positive number when a > b and zero when
+// a == b.
+func (c AppConfig) Compare(a, b string) int {
+	if c.Key == 0 {
+		return strings.Compare(a, b)
+	}
+
+	return strings.Compare(field(a, c.Key), field(b, c.Key))
+}
+
+// field returns the n-th (counting from 1) whitespace-separated field of
+// the line or an empty string if line has less fields.
+func field(line string, n int) string {
+	fields := strings.Fields(line)
+	if n > len(fields) {
+		return ""
+	}
+
+	return fields[n-1]
+}
+
 // Version returns string with full version description.
 func (c AppConfig) Version() string {
 	build := ""
@@ -131,3 +371,8 @@ func NewAppContext(config AppConfig) (context.Context, context.CancelFunc) {
 
 	return ctx, cancel
 }
+
+// NewSeed returns a non-zero seed for random number generator.
+func NewSeed() int64 {
+	return rand.Int63n(math.MaxInt64-1) + 1
+}
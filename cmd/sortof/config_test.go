@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/macie/sortof"
 )
 
 func TestNewAppConfig(t *testing.T) {
@@ -14,24 +17,61 @@ func TestNewAppConfig(t *testing.T) {
 	}{
 		{[]string{"-h"}, AppConfig{ExitMessage: helpMsg}},
 		{[]string{"-v"}, AppConfig{ExitMessage: "sortof local-dev (hardened)"}},
-		{[]string{"bogo"}, AppConfig{SortFunc: BogosortFile}},
-		{[]string{"bogo", "some_file"}, AppConfig{SortFunc: BogosortFile, Files: []string{"some_file"}}},
-		{[]string{"bogo", "-t", "1s"}, AppConfig{SortFunc: BogosortFile, Timeout: time.Second}},
+		{[]string{"bogo"}, AppConfig{Algorithm: lookup("bogo")}},
+		{[]string{"bogo", "some_file"}, AppConfig{Algorithm: lookup("bogo"), Files: []string{"some_file"}}},
+		{[]string{"bogo", "-t", "1s"}, AppConfig{Algorithm: lookup("bogo"), Timeout: time.Second}},
 		{[]string{"bogo", "-t", "11s", "first_file", "second_file"}, AppConfig{
-			SortFunc: BogosortFile, Timeout: 11 * time.Second, Files: []string{"first_file", "second_file"},
+			Algorithm: lookup("bogo"), Timeout: 11 * time.Second, Files: []string{"first_file", "second_file"},
+		}},
+		{[]string{"bogo", "-seed", "42"}, AppConfig{Algorithm: lookup("bogo"), Seed: 42}},
+		{[]string{"bogo", "--seed", "-7", "-t", "1s", "some_file"}, AppConfig{
+			Algorithm: lookup("bogo"), Seed: -7, Timeout: time.Second, Files: []string{"some_file"},
+		}},
+		{[]string{"bogo", "--lock-prefix", "-seed", "1"}, AppConfig{
+			Algorithm: lookup("bogo"), Seed: 1, LockPrefix: true,
+		}},
+		{[]string{"bogo", "-j", "4"}, AppConfig{Algorithm: lookup("bogo"), Jobs: 4}},
+		{[]string{"bogo", "-j", "2", "-stable", "-lock-prefix"}, AppConfig{
+			Algorithm: lookup("bogo"), Jobs: 2, Stable: true, LockPrefix: true,
+		}},
+		{[]string{"miracle", "--cosmic-rate", "2.5"}, AppConfig{Algorithm: lookup("miracle"), CosmicRate: 2.5}},
+		{[]string{"miracle", "-t", "1m", "-watch", "some_file"}, AppConfig{
+			Algorithm: lookup("miracle"), Timeout: time.Minute, Watch: "some_file",
 		}},
-		{[]string{"slow"}, AppConfig{SortFunc: SlowsortFile}},
-		{[]string{"slow", "-t", "5ns"}, AppConfig{SortFunc: SlowsortFile, Timeout: 5 * time.Nanosecond}},
+		{[]string{"slow"}, AppConfig{Algorithm: lookup("slow")}},
+		{[]string{"slow", "--stats"}, AppConfig{Algorithm: lookup("slow"), Stats: true}},
+		{[]string{"miracle", "--fallback-after", "10s"}, AppConfig{Algorithm: lookup("miracle"), FallbackAfter: 10 * time.Second}},
+		{[]string{"bogo", "-t", "5s", "--best-effort"}, AppConfig{
+			Algorithm: lookup("bogo"), Timeout: 5 * time.Second, BestEffort: true,
+		}},
+		{[]string{"bogo", "--max-shuffles", "10", "-max-comparisons", "50"}, AppConfig{
+			Algorithm: lookup("bogo"), MaxShuffles: 10, MaxComparisons: 50,
+		}},
+		{[]string{"slow", "-k", "2", "--stable"}, AppConfig{Algorithm: lookup("slow"), Key: 2, Stable: true}},
+		{[]string{"slow", "-t", "5ns"}, AppConfig{Algorithm: lookup("slow"), Timeout: 5 * time.Nanosecond}},
 		{[]string{"slow", "-t", "5ns", "-"}, AppConfig{
-			SortFunc: SlowsortFile, Timeout: 5 * time.Nanosecond, Files: []string{"-"},
+			Algorithm: lookup("slow"), Timeout: 5 * time.Nanosecond, Files: []string{"-"},
+		}},
+		{[]string{"slow", "-refuse-above", "1m"}, AppConfig{Algorithm: lookup("slow"), RefuseAbove: time.Minute}},
+		{[]string{"estimate", "bogo", "-k", "2", "some_file"}, AppConfig{
+			Command: "estimate", Algorithm: lookup("bogo"), Key: 2, Files: []string{"some_file"},
+		}},
+		{[]string{"simulate", "bogo", "-n", "5"}, AppConfig{
+			Command: "simulate", Algorithm: lookup("bogo"), N: 5, Trials: defaultTrials,
 		}},
-		{[]string{"stalin"}, AppConfig{SortFunc: StalinsortFile}},
-		{[]string{"stalin", "-t", "2h"}, AppConfig{SortFunc: StalinsortFile, Timeout: 2 * time.Hour}},
+		{[]string{"simulate", "slow", "-n", "8", "-trials", "3", "-csv"}, AppConfig{
+			Command: "simulate", Algorithm: lookup("slow"), N: 8, Trials: 3, CSV: true,
+		}},
+		{[]string{"generate", "--adversary", "stalin", "-n", "10"}, AppConfig{
+			Command: "generate", Algorithm: lookup("stalin"), N: 10,
+		}},
+		{[]string{"stalin"}, AppConfig{Algorithm: lookup("stalin")}},
+		{[]string{"stalin", "-t", "2h"}, AppConfig{Algorithm: lookup("stalin"), Timeout: 2 * time.Hour}},
 		{[]string{"stalin", "-t", "2h", "-", "some_file"}, AppConfig{
-			SortFunc: StalinsortFile, Timeout: 2 * time.Hour, Files: []string{"-", "some_file"},
+			Algorithm: lookup("stalin"), Timeout: 2 * time.Hour, Files: []string{"-", "some_file"},
 		}},
 		{[]string{"stalin", "-t", "2h", "some_file", "-"}, AppConfig{
-			SortFunc: StalinsortFile, Timeout: 2 * time.Hour, Files: []string{"some_file", "-"},
+			Algorithm: lookup("stalin"), Timeout: 2 * time.Hour, Files: []string{"some_file", "-"},
 		}},
 	}
 	for _, tc := range testcases {
@@ -49,6 +89,78 @@ func TestNewAppConfig(t *testing.T) {
 	}
 }
 
+// lookup returns the registered algorithm with the given name.
+func lookup(name string) sortof.Algorithm {
+	a, _ := sortof.Lookup(name)
+	return a
+}
+
+func TestNewAppConfigInvalid(t *testing.T) {
+	testcases := [][]string{
+		{"quick"},
+		{"slow", "-k", "-1"},
+		{"bogo", "-cosmic-rate", "1"},
+		{"bogo", "-j", "-2"},
+		{"miracle", "-cosmic-rate", "-1"},
+		{"bogo", "-max-shuffles", "-1"},
+		{"bogo", "-fallback-after", "-1s"},
+		{"slow", "-max-comparisons", "-5"},
+		{"bogo", "-watch", "some_file"},
+		{"bogo", "-refuse-above", "-1s"},
+		{"miracle", "-cosmic-rate", "1", "-refuse-above", "1s"},
+		{"estimate"},
+		{"estimate", "quick"},
+		{"estimate", "miracle", "-watch", "some_file"},
+		{"simulate", "bogo"},
+		{"simulate", "bogo", "-n", "5", "-trials", "-1"},
+		{"simulate", "bogo", "-n", "5", "some_file"},
+		{"bogo", "-n", "5"},
+		{"estimate", "bogo", "-csv"},
+		{"generate", "-n", "5"},
+		{"generate", "-adversary", "quick", "-n", "5"},
+		{"generate", "-adversary", "slow"},
+		{"generate", "-adversary", "slow", "-n", "5", "some_file"},
+		{"slow", "-adversary", "slow"},
+		{"miracle", "-watch", "some_file", "other_file"},
+		{"miracle", "-watch", "some_file", "-cosmic-rate", "1"},
+		{"miracle", "-watch", "some_file", "-fallback-after", "1s"},
+		{"miracle", "-watch", "some_file", "-stats"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(strings.Join(tc, "_"), func(t *testing.T) {
+			t.Parallel()
+			got, err := NewAppConfig(tc)
+			if err == nil {
+				t.Errorf("NewAppConfig(%v) = %v, want error", tc, got)
+			}
+		})
+	}
+}
+
+func TestAppConfigCompare(t *testing.T) {
+	testcases := []struct {
+		key  int
+		a, b string
+		want int
+	}{
+		{0, "a 2", "b 1", -1},
+		{2, "a 2", "b 1", 1},
+		{2, "a  1", "b\t1", 0},
+		{3, "a 1", "b 1 c", -1},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprintf("%d_%s_%s", tc.key, tc.a, tc.b), func(t *testing.T) {
+			t.Parallel()
+			config := AppConfig{Key: tc.key}
+			if got := config.Compare(tc.a, tc.b); got != tc.want {
+				t.Errorf("AppConfig{Key: %d}.Compare(%q, %q) = %d, want %d", tc.key, tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
 func FuzzNewAppConfig(f *testing.F) {
 	validArgs := []*regexp.Regexp{
 		regexp.MustCompile(`\-h`), regexp.MustCompile(`\-v`),
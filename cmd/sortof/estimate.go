@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/macie/sortof"
+)
+
+// calibrationTime is the duration of the sample run, which measures the speed
+// of sorting on the current machine.
+const calibrationTime = 100 * time.Millisecond
+
+// secondsPerYear is the length of the Julian year.
+const secondsPerYear = 365.25 * 24 * 60 * 60
+
+// EstimateFile returns the expected work of sorting lines from the file by
+// config.Algorithm with settings from other config fields and the expected
+// time of sorting in seconds. A context controls cancellation.
+func EstimateFile(ctx context.Context, file io.Reader, config AppConfig) (sortof.Estimation, float64, error) {
+	lines, err := readLines(ctx, file)
+	if err != nil {
+		return sortof.Estimation{}, 0, err
+	}
+
+	return estimateLines(ctx, lines, config)
+}
+
+// estimateLines returns the expected work and time of sorting lines (see
+// calibrate and expect).
+func estimateLines(ctx context.Context, lines []string, config AppConfig) (sortof.Estimation, float64, error) {
+	c, err := calibrate(ctx, lines, config)
+	if err != nil {
+		return sortof.Estimation{}, 0, err
+	}
+
+	return expect(lines, c, config)
+}
+
+// calibration is the result of the sample run.
+type calibration struct {
+	sorted   []string     // sorted copy of lines, when the run finished
+	finished bool         // whether the run finished within calibrationTime
+	stats    sortof.Stats // work and time of the run
+}
+
+// calibrate sorts a copy of lines by config.Algorithm for calibrationTime and
+// returns the result. Limits of work from config are not applied.
+func calibrate(ctx context.Context, lines []string, config AppConfig) (calibration, error) {
+	var c calibration
+	sampleCtx, cancel := context.WithTimeout(ctx, calibrationTime)
+	defer cancel()
+	sorted := slices.Clone(lines)
+	n, err := config.Algorithm.Sort(sampleCtx, sortof.WrapSlice(sorted, config.Compare), sampleOptions(config, &c.stats)...)
+	switch {
+	case ctx.Err() != nil:
+		return calibration{}, context.Cause(ctx)
+	case err == nil:
+		c.sorted, c.finished = sorted[:n], true
+	case !errors.Is(err, context.DeadlineExceeded):
+		return calibration{}, err
+	}
+
+	return c, nil
+}
+
+// expect returns the expected work and time of sorting lines based on
+// the calibration c. When the sample run finished, its work and time are
+// returned, so the actual order of lines counts. Otherwise the work is
+// expected for lines in random order (see sortof.Estimate), and the time is
+// calibrated by the measured time of a single comparison (together with
+// the rest of the work done between comparisons). With config.FallbackAfter
+// the time is at most that long.
+func expect(lines []string, c calibration, config AppConfig) (sortof.Estimation, float64, error) {
+	if c.finished {
+		return measured(c.stats), fallbackTime(c.stats.Duration.Seconds(), config), nil
+	}
+
+	estimation, err := sortof.Estimate(config.Algorithm, len(lines), duplicates(lines, config), sampleOptions(config, new(sortof.Stats))...)
+	if err != nil {
+		return sortof.Estimation{}, 0, err
+	}
+	expected := math.Inf(1)
+	if !math.IsInf(estimation.Comparisons, 1) && c.stats.Comparisons > 0 {
+		expected = estimation.Comparisons * c.stats.Duration.Seconds() / float64(c.stats.Comparisons)
+	}
+
+	return estimation, fallbackTime(expected, config), nil
+}
+
+// sampleOptions returns options of sorting by config without limits of work,
+// which would stop the sample run before it is calibrated.
+func sampleOptions(config AppConfig, stats *sortof.Stats) []sortof.Option {
+	config.MaxShuffles, config.MaxComparisons, config.FallbackAfter = 0, 0, 0
+
+	return sortOptions(config, stats)
+}
+
+// withinLimits reports whether sorting by config would do the work recorded in
+// stats without exceeding its limits, so the result of the sample run can be
+// used instead of sorting again.
+func withinLimits(stats sortof.Stats, config AppConfig) bool {
+	exceeds := func(used, limit int64) bool {
+		return limit > 0 && used > limit
+	}
+
+	return !exceeds(stats.Shuffles, config.MaxShuffles) &&
+		!exceeds(stats.Comparisons, config.MaxComparisons) &&
+		!exceeds(int64(stats.Duration), int64(config.FallbackAfter))
+}
+
+// measured returns the work recorded in stats.
+func measured(stats sortof.Stats) sortof.Estimation {
+	return sortof.Estimation{
+		Comparisons: float64(stats.Comparisons),
+		Swaps:       float64(stats.Swaps),
+		Shuffles:    float64(stats.Shuffles),
+		Calls:       float64(stats.Calls),
+		Sleeps:      float64(stats.Sleeps),
+	}
+}
+
+// fallbackTime returns the time of sorting in seconds bounded by
+// config.FallbackAfter, after which lines are sorted by the fallback.
+func fallbackTime(s float64, config AppConfig) float64 {
+	if config.FallbackAfter > 0 {
+		return min(s, config.FallbackAfter.Seconds())
+	}
+
+	return s
+}
+
+// duplicates returns sizes of groups of lines with equal keys.
+func duplicates(lines []string, config AppConfig) []int {
+	sorted := slices.Clone(lines)
+	slices.SortFunc(sorted, config.Compare)
+	var groups []int
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && config.Compare(sorted[i], sorted[j]) == 0 {
+			j++
+		}
+		if j-i > 1 {
+			groups = append(groups, j-i)
+		}
+		i = j
+	}
+
+	return groups
+}
+
+// formatSeconds returns human-readable description of the time in seconds.
+// Times longer than a year are described in years, because they overflow
+// time.Duration quickly.
+func formatSeconds(s float64) string {
+	switch {
+	case math.IsInf(s, 1):
+		return "forever"
+	case s >= secondsPerYear:
+		return fmt.Sprintf("%.3g years", s/secondsPerYear)
+	default:
+		return time.Duration(s * float64(time.Second)).String()
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macie/sortof"
+)
+
+func TestEstimateFile(t *testing.T) {
+	ctx := context.Background()
+	testcases := []struct {
+		algorithm string
+		input     string
+		want      sortof.Estimation
+	}{
+		{"bogo", "a\nb\n", sortof.Estimation{Comparisons: 1}},
+		{"miracle", "b\na\n", sortof.Estimation{Comparisons: math.Inf(1), Sleeps: math.Inf(1)}},
+		{"miracle", "a\na\n", sortof.Estimation{Comparisons: 1}},
+		{"slow", "c\nb\na\n", sortof.Estimation{Comparisons: 3, Swaps: 3, Calls: 10}},
+		{"stalin", "", sortof.Estimation{}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.algorithm, []byte(tc.input)), func(t *testing.T) {
+			t.Parallel()
+			config := AppConfig{Command: "estimate", Algorithm: lookup(tc.algorithm), Seed: 1}
+
+			got, expected, err := EstimateFile(ctx, strings.NewReader(tc.input), config)
+			if err != nil {
+				t.Errorf("EstimateFile(%v, %q, %v) returns error: %v", ctx, tc.input, config, err)
+			}
+			if got != tc.want {
+				t.Errorf("EstimateFile(%v, %q, %v) = %v, want %v", ctx, tc.input, config, got, tc.want)
+			}
+			if expected < 0 || math.IsInf(expected, 1) != math.IsInf(tc.want.Comparisons, 1) {
+				t.Errorf("EstimateFile(%v, %q, %v) expects time %v", ctx, tc.input, config, expected)
+			}
+		})
+	}
+}
+
+func TestEstimateFileRandomOrder(t *testing.T) {
+	ctx := context.Background()
+	input := "l\nk\nj\ni\nh\ng\nf\ne\nd\nc\nb\na\n"
+	config := AppConfig{Command: "estimate", Algorithm: lookup("bogo"), Seed: 1}
+
+	got, expected, err := EstimateFile(ctx, strings.NewReader(input), config)
+	if err != nil {
+		t.Errorf("EstimateFile(%v, %q, %v) returns error: %v", ctx, input, config, err)
+	}
+	if want, _ := sortof.Estimate(config.Algorithm, 12, nil); got != want {
+		t.Errorf("EstimateFile(%v, %q, %v) = %v, want %v", ctx, input, config, got, want)
+	}
+	if expected < calibrationTime.Seconds() || math.IsInf(expected, 1) {
+		t.Errorf("EstimateFile(%v, %q, %v) expects time %v", ctx, input, config, expected)
+	}
+
+	config.FallbackAfter = time.Second
+	if _, expected, _ := EstimateFile(ctx, strings.NewReader(input), config); expected != 1 {
+		t.Errorf("EstimateFile(%v, %q, %v) expects time %v, want 1", ctx, input, config, expected)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	lines := []string{"b 1", "a 2", "b 1", "c 1", "b 1"}
+	testcases := []struct {
+		config AppConfig
+		want   []int
+	}{
+		{AppConfig{}, []int{3}},
+		{AppConfig{Key: 2}, []int{4}},
+	}
+	for _, tc := range testcases {
+		if got := duplicates(lines, tc.config); !slices.Equal(got, tc.want) {
+			t.Errorf("duplicates(%q, %v) = %v, want %v", lines, tc.config, got, tc.want)
+		}
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	testcases := map[float64]string{
+		0:                  "0s",
+		1.5:                "1.5s",
+		3600:               "1h0m0s",
+		secondsPerYear * 2: "2 years",
+		1e300:              "3.17e+292 years",
+		math.Inf(1):        "forever",
+	}
+	for s, want := range testcases {
+		if got := formatSeconds(s); got != want {
+			t.Errorf("formatSeconds(%v) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestSortFileRefuseAbove(t *testing.T) {
+	ctx := context.Background()
+	input := "j\ni\nh\ng\nf\ne\nd\nc\nb\na\n"
+	config := AppConfig{Algorithm: lookup("bogo"), Seed: 1, RefuseAbove: time.Nanosecond}
+
+	got, _, err := SortFile(ctx, nopCloser{strings.NewReader(input)}, config)
+	if err == nil || !strings.Contains(err.Error(), "-refuse-above") {
+		t.Errorf("SortFile(%v, %q, %v) returns error: %v, want refusal", ctx, input, config, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("SortFile(%v, %q, %v) = %v, want no lines", ctx, input, config, got)
+	}
+}
+
+func TestSortFileRefuseAboveNotNeeded(t *testing.T) {
+	ctx := context.Background()
+	reversed := "o\nn\nm\nl\nk\nj\ni\nh\ng\nf\ne\nd\nc\nb\na\n"
+	sorted := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\no\n"
+	testcases := map[string]struct {
+		input  string
+		config AppConfig
+	}{
+		"stalin": {reversed, AppConfig{Algorithm: lookup("stalin"), RefuseAbove: time.Minute}},
+		"bogo -lock-prefix": {reversed, AppConfig{
+			Algorithm: lookup("bogo"), Seed: 1, LockPrefix: true, RefuseAbove: 10 * time.Second,
+		}},
+		"bogo -lock-prefix -j 2": {reversed, AppConfig{
+			Algorithm: lookup("bogo"), Seed: 1, LockPrefix: true, Jobs: 2, RefuseAbove: 10 * time.Second,
+		}},
+		"bogo -fallback-after": {reversed, AppConfig{
+			Algorithm: lookup("bogo"), Seed: 1, FallbackAfter: time.Millisecond, RefuseAbove: time.Second,
+		}},
+		"bogo sorted":    {sorted, AppConfig{Algorithm: lookup("bogo"), Seed: 1, RefuseAbove: time.Second}},
+		"miracle sorted": {sorted, AppConfig{Algorithm: lookup("miracle"), RefuseAbove: time.Second}},
+	}
+	for name, tc := range testcases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, err := SortFile(ctx, nopCloser{strings.NewReader(tc.input)}, tc.config)
+			if err != nil {
+				t.Fatalf("SortFile(%v, %q, %v) returns error: %v", ctx, tc.input, tc.config, err)
+			}
+			if !slices.IsSorted(got) || len(got) == 0 {
+				t.Errorf("SortFile(%v, %q, %v) cannot sort; got %v", ctx, tc.input, tc.config, got)
+			}
+		})
+	}
+}
+
+func TestSortFileRefuseAboveSortedOnce(t *testing.T) {
+	ctx := context.Background()
+	input := "c\nb\na\n"
+	calls := 0
+	config := AppConfig{Algorithm: lookup("bogo"), Seed: 1, RefuseAbove: time.Second}
+	sort := config.Algorithm.Sort
+	config.Algorithm.Sort = func(ctx context.Context, data sortof.Interface, opts ...sortof.Option) (int, error) {
+		calls++
+		return sort(ctx, data, opts...)
+	}
+
+	got, _, err := SortFile(ctx, nopCloser{strings.NewReader(input)}, config)
+	if err != nil {
+		t.Fatalf("SortFile(%v, %q, %v) returns error: %v", ctx, input, config, err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("SortFile(%v, %q, %v) = %v, want %v", ctx, input, config, got, want)
+	}
+	if calls != 1 {
+		t.Errorf("SortFile(%v, %q, %v) sorts lines %d times, want 1", ctx, input, config, calls)
+	}
+}
+
+func TestSortFileRefuseAboveLimited(t *testing.T) {
+	ctx := context.Background()
+	input := "e\nd\nc\nb\na\n"
+	config := AppConfig{Algorithm: lookup("bogo"), Seed: 1, MaxComparisons: 1, RefuseAbove: time.Second}
+
+	// the sample run is not limited, but its result exceeds the budget
+	_, _, err := SortFile(ctx, nopCloser{strings.NewReader(input)}, config)
+	var budgetErr *sortof.BudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Errorf("SortFile(%v, %q, %v) returns error: %v, want *sortof.BudgetError", ctx, input, config, err)
+	}
+}
+
+// nopCloser is an io.ReadCloser which does nothing on Close.
+type nopCloser struct{ *strings.Reader }
+
+func (nopCloser) Close() error { return nil }
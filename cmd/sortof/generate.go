@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/macie/sortof/adversary"
+)
+
+// GenerateLines returns lines of the pessimal input of config.N numbers for
+// config.Algorithm (see adversary.Generate). Numbers are padded with zeros to
+// equal width, so lines are ordered in the same way as numbers. A context
+// controls cancellation.
+func GenerateLines(ctx context.Context, config AppConfig) ([]string, error) {
+	x, err := adversary.Generate(ctx, config.Algorithm, config.N)
+	if err != nil {
+		return []string{}, err
+	}
+
+	width := len(strconv.Itoa(max(config.N-1, 0)))
+	lines := make([]string, len(x))
+	for i, v := range x {
+		lines[i] = fmt.Sprintf("%0*d", width, v)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestGenerateLines(t *testing.T) {
+	ctx := context.Background()
+	testcases := []struct {
+		algorithm string
+		n         int
+		want      []string
+	}{
+		{"bogo", 3, []string{"2", "1", "0"}},
+		{"slow", 1, []string{"0"}},
+		{"stalin", 11, []string{"10", "00", "01", "02", "03", "04", "05", "06", "07", "08", "09"}},
+	}
+	for _, tc := range testcases {
+		config := AppConfig{Command: "generate", Algorithm: lookup(tc.algorithm), N: tc.n}
+
+		got, err := GenerateLines(ctx, config)
+		if err != nil {
+			t.Errorf("GenerateLines(%v, %v) returns error: %v", ctx, config, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("GenerateLines(%v, %v) = %v, want %v", ctx, config, got, tc.want)
+		}
+	}
+}
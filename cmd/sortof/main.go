@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -29,6 +30,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	if config.Command == "simulate" || (config.Command == "" && (!config.Algorithm.Deterministic || config.CosmicRate > 0)) {
+		if config.Seed == 0 {
+			config.Seed = NewSeed()
+		}
+		log.Printf("random seed: %d", config.Seed)
+	}
+
 	ctx, cancel := NewAppContext(config)
 	defer cancel()
 
@@ -53,24 +61,81 @@ func main() {
 		files = []io.ReadCloser{os.Stdin}
 	}
 
-	for _, file := range files {
-		sorted, err := config.SortFunc(ctx, file)
+	if config.Command == "generate" {
+		lines, err := GenerateLines(ctx, config)
 		if err != nil {
-			switch {
-			case err == context.Canceled:
-				log.Println("sorting cancelled by user")
-			case err == context.DeadlineExceeded:
-				log.Println("sorting needs more time than expected")
-			default:
-				log.Println(err)
-			}
+			exitWithError(err)
+		}
+		for _, v := range lines {
+			fmt.Fprintln(os.Stdout, v)
+		}
+		os.Exit(0)
+	}
+
+	if config.Command == "simulate" {
+		trials, err := Simulate(ctx, config)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := PrintSimulation(os.Stdout, os.Stderr, config, trials); err != nil {
+			log.Println(err)
 			os.Exit(1)
 		}
+		os.Exit(0)
+	}
 
+	if config.Command == "estimate" {
+		for _, file := range files {
+			estimation, expected, err := EstimateFile(ctx, file, config)
+			if err != nil {
+				exitWithError(err)
+			}
+			fmt.Fprintf(os.Stdout, "expected work: %v\nexpected time: %s\n", estimation, formatSeconds(expected))
+		}
+		os.Exit(0)
+	}
+
+	if config.Watch != "" {
+		sorted, err := MiraclesortWatch(ctx, config.Watch, config)
+		if err != nil {
+			exitWithError(err)
+		}
 		for _, v := range sorted {
 			fmt.Fprintln(os.Stdout, v)
 		}
+		os.Exit(0)
+	}
+
+	for _, file := range files {
+		sorted, stats, err := SortFile(ctx, file, config)
+		if config.Stats {
+			log.Printf("stats: %v", stats)
+		}
+		if stats.Fallbacks > 0 {
+			log.Println("sorting gave up; lines sorted by fallback algorithm")
+		}
+
+		for _, v := range sorted {
+			fmt.Fprintln(os.Stdout, v)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
 	}
 
 	os.Exit(0)
 }
+
+// exitWithError prints description of the sorting error and exits
+// the program.
+func exitWithError(err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		log.Println("sorting cancelled by user")
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Println("sorting needs more time than expected")
+	default:
+		log.Println(err)
+	}
+	os.Exit(1)
+}
@@ -28,7 +28,7 @@ func Sandbox() error {
 		"sched_yield", "set_robust_list", "sigaltstack", "tgkill", "write",
 
 		// similar to rpath pledge
-		"openat",
+		"close", "fstat", "newfstatat", "openat",
 	}
 
 	// By default goroutines don't play well with seccomp. Program will hang
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/macie/sortof"
+)
+
+// histogramBins is the maximum number of bins of the simulation histogram.
+const histogramBins = 10
+
+// histogramWidth is the length of the longest bar of the ASCII histogram.
+const histogramWidth = 50
+
+// Trial is the result of sorting a single input of the simulation.
+type Trial struct {
+	sortof.Stats
+	Censored bool // sorting was stopped by a limit before it finished
+}
+
+// Simulate sorts config.Trials random permutations of config.N distinct
+// numbers by config.Algorithm with settings from other config fields and
+// returns statistics of each trial. Inputs and seeds of the algorithm are
+// generated from config.Seed, so the simulation can be repeated. Trials
+// stopped by a budget or by the timeout are censored. The timeout stops
+// the whole simulation, so remaining trials are not run. Algorithms which are
+// not expected to sort random inputs ever (see sortof.Estimate) are rejected
+// unless config limits time or work of sorting in a way which applies to
+// them. A context controls cancellation.
+func Simulate(ctx context.Context, config AppConfig) ([]Trial, error) {
+	if expected, err := expectedWork(config); err == nil && math.IsInf(expected.Comparisons, 1) && !limited(config, expected) {
+		return nil, fmt.Errorf("algorithm '%s' never finishes sorting random inputs of %d elements. Use -fallback-after", config.Algorithm.Name, config.N)
+	}
+
+	r := rand.New(rand.NewSource(config.Seed))
+	trials := make([]Trial, 0, config.Trials)
+	for i := 0; i < config.Trials; i++ {
+		x := r.Perm(config.N)
+		trial := config
+		trial.Seed = r.Int63()
+
+		var stats sortof.Stats
+		_, err := config.Algorithm.Sort(ctx, sortof.WrapSlice(x, cmp.Compare[int]), sortOptions(trial, &stats)...)
+		var budgetErr *sortof.BudgetError
+		switch {
+		case err == nil:
+			trials = append(trials, Trial{Stats: stats})
+		case errors.As(err, &budgetErr):
+			trials = append(trials, Trial{Stats: stats, Censored: true})
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return append(trials, Trial{Stats: stats, Censored: true}), nil
+		default:
+			return trials, err
+		}
+	}
+
+	return trials, nil
+}
+
+// limited reports whether config limits time or work of sorting by
+// the algorithm, which is expected to do the work. Only the fallback makes
+// the sorting finish, other limits stop it.
+func limited(config AppConfig, expected sortof.Estimation) bool {
+	return config.Timeout > 0 || config.FallbackAfter > 0 || config.MaxComparisons > 0 ||
+		(config.MaxShuffles > 0 && expected.Shuffles > 0)
+}
+
+// PrintSimulation prints the summary of trials of the simulation described by
+// config together with values expected by theory (see sortof.Estimate), and
+// the histogram of comparisons. With config.CSV the histogram is printed to
+// stdout as CSV and the summary to stderr. Otherwise both are printed to
+// stdout. Censored trials are only counted, because their work is not
+// complete.
+func PrintSimulation(stdout, stderr io.Writer, config AppConfig, trials []Trial) error {
+	var shuffles, comparisons, durations []float64
+	var counts []int64
+	censored := 0
+	for _, s := range trials {
+		if s.Censored {
+			censored++
+			continue
+		}
+		shuffles = append(shuffles, float64(s.Shuffles))
+		comparisons = append(comparisons, float64(s.Comparisons))
+		durations = append(durations, float64(s.Duration))
+		counts = append(counts, s.Comparisons)
+	}
+	expected, err := expectedWork(config)
+	if err != nil {
+		expected = sortof.Estimation{Shuffles: math.NaN(), Comparisons: math.NaN()}
+	}
+	formatCount := func(v float64) string {
+		if math.IsNaN(v) {
+			return "-"
+		}
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	}
+	formatDuration := func(v float64) string { return time.Duration(v).String() }
+
+	summary := stdout
+	if config.CSV {
+		summary = stderr
+	}
+	fmt.Fprintf(summary, "trials: %d, n: %d\n", len(trials), config.N)
+	if censored > 0 {
+		fmt.Fprintf(summary, "censored: %d (stopped by a limit, not included below)\n", censored)
+	}
+	if len(counts) == 0 {
+		fmt.Fprintf(summary, "no trial finished sorting\n")
+		return nil
+	}
+	fmt.Fprintf(summary, "%-12s %12s %12s %12s %12s %12s\n", "", "expected", "mean", "median", "p95", "max")
+	printSummary(summary, "shuffles", formatCount(expected.Shuffles), summarize(shuffles), formatCount)
+	printSummary(summary, "comparisons", formatCount(expected.Comparisons), summarize(comparisons), formatCount)
+	printSummary(summary, "time", "-", summarize(durations), formatDuration)
+
+	bins := histogram(counts)
+	if config.CSV {
+		w := csv.NewWriter(stdout)
+		w.Write([]string{"comparisons_from", "comparisons_to", "trials"})
+		for _, b := range bins {
+			w.Write([]string{strconv.FormatInt(b.From, 10), strconv.FormatInt(b.To, 10), strconv.Itoa(b.Count)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	fmt.Fprintf(stdout, "\ncomparisons:\n")
+	maxCount := 0
+	for _, b := range bins {
+		maxCount = max(maxCount, b.Count)
+	}
+	for _, b := range bins {
+		bar := strings.Repeat("#", b.Count*histogramWidth/maxCount)
+		fmt.Fprintf(stdout, "%12d - %-12d |%-*s %d\n", b.From, b.To, histogramWidth, bar, b.Count)
+	}
+
+	return nil
+}
+
+// expectedWork returns the work of sorting config.N distinct elements in
+// random order expected by theory for settings from config.
+func expectedWork(config AppConfig) (sortof.Estimation, error) {
+	var stats sortof.Stats
+	return sortof.Estimate(config.Algorithm, config.N, nil, sortOptions(config, &stats)...)
+}
+
+// printSummary prints a row of the summary table.
+func printSummary(w io.Writer, name, expected string, s summary, format func(float64) string) {
+	fmt.Fprintf(w, "%-12s %12s %12s %12s %12s %12s\n", name, expected, format(s.Mean), format(s.Median), format(s.P95), format(s.Max))
+}
+
+// summary describes the distribution of a measure of sorting work.
+type summary struct {
+	Mean, Median, P95, Max float64
+}
+
+// summarize returns the summary of values. The 95th percentile is computed
+// with the nearest-rank method.
+func summarize(values []float64) summary {
+	if len(values) == 0 {
+		return summary{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return summary{
+		Mean:   sum / float64(n),
+		Median: (sorted[(n-1)/2] + sorted[n/2]) / 2,
+		P95:    sorted[int(math.Ceil(0.95*float64(n)))-1],
+		Max:    sorted[n-1],
+	}
+}
+
+// bin is a range of values of a histogram with the number of values in it.
+type bin struct {
+	From, To int64
+	Count    int
+}
+
+// histogram returns at most histogramBins bins of equal width, which cover
+// all values.
+func histogram(values []int64) []bin {
+	if len(values) == 0 {
+		return nil
+	}
+
+	lo, hi := slices.Min(values), slices.Max(values)
+	width := (hi-lo)/histogramBins + 1
+	bins := make([]bin, (hi-lo)/width+1)
+	for i := range bins {
+		bins[i] = bin{From: lo + int64(i)*width, To: lo + int64(i+1)*width - 1}
+	}
+	for _, v := range values {
+		bins[(v-lo)/width].Count++
+	}
+
+	return bins
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macie/sortof"
+)
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+	config := AppConfig{Command: "simulate", Algorithm: lookup("bogo"), N: 4, Trials: 20, Seed: 1}
+
+	got, err := Simulate(ctx, config)
+	if err != nil {
+		t.Fatalf("Simulate(%v, %v) returns error: %v", ctx, config, err)
+	}
+	if len(got) != config.Trials {
+		t.Errorf("Simulate(%v, %v) returns %d trials, want %d", ctx, config, len(got), config.Trials)
+	}
+	again, _ := Simulate(ctx, config)
+	shuffles := func(s Trial) int64 { return s.Shuffles }
+	if a, b := mapStats(got, shuffles), mapStats(again, shuffles); !slices.Equal(a, b) {
+		t.Errorf("Simulate(%v, %v) is not repeatable; got shuffles %v and %v", ctx, config, a, b)
+	}
+}
+
+func TestSimulateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := AppConfig{Command: "simulate", Algorithm: lookup("miracle"), N: 4, Trials: 2, Seed: 1, Timeout: time.Hour}
+
+	if _, err := Simulate(ctx, config); err != context.Canceled {
+		t.Errorf("Simulate(%v, %v) returns error: %v, want %v", ctx, config, err, context.Canceled)
+	}
+}
+
+func TestSimulateNeverFinishes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	config := AppConfig{Command: "simulate", Algorithm: lookup("miracle"), N: 4, Trials: 2, Seed: 1}
+
+	if _, err := Simulate(ctx, config); err == nil || !strings.Contains(err.Error(), "never finishes") {
+		t.Errorf("Simulate(%v, %v) returns error: %v, want rejection", ctx, config, err)
+	}
+
+	// miracles do not shuffle
+	config.MaxShuffles = 10
+	if _, err := Simulate(ctx, config); err == nil || !strings.Contains(err.Error(), "never finishes") {
+		t.Errorf("Simulate(%v, %v) returns error: %v, want rejection", ctx, config, err)
+	}
+
+	config.MaxShuffles = 0
+	config.FallbackAfter = time.Millisecond
+	got, err := Simulate(ctx, config)
+	if err != nil {
+		t.Errorf("Simulate(%v, %v) returns error: %v", ctx, config, err)
+	}
+	if len(got) != config.Trials {
+		t.Errorf("Simulate(%v, %v) returns %d trials, want %d", ctx, config, len(got), config.Trials)
+	}
+}
+
+func TestSimulateCensored(t *testing.T) {
+	ctx := context.Background()
+	config := AppConfig{Command: "simulate", Algorithm: lookup("bogo"), N: 6, Trials: 50, Seed: 1, MaxShuffles: 100}
+
+	got, err := Simulate(ctx, config)
+	if err != nil {
+		t.Fatalf("Simulate(%v, %v) returns error: %v", ctx, config, err)
+	}
+	if len(got) != config.Trials {
+		t.Errorf("Simulate(%v, %v) returns %d trials, want %d", ctx, config, len(got), config.Trials)
+	}
+	censored := func(s Trial) bool { return s.Censored }
+	if !slices.ContainsFunc(got, censored) {
+		t.Errorf("Simulate(%v, %v) returns no censored trials", ctx, config)
+	}
+}
+
+func TestSimulateTimeout(t *testing.T) {
+	config := AppConfig{Command: "simulate", Algorithm: lookup("miracle"), N: 4, Trials: 2, Seed: 1, Timeout: 10 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	got, err := Simulate(ctx, config)
+	if err != nil {
+		t.Fatalf("Simulate(%v, %v) returns error: %v", ctx, config, err)
+	}
+	if len(got) == 0 || !got[len(got)-1].Censored {
+		t.Errorf("Simulate(%v, %v) = %v, want the last trial censored", ctx, config, got)
+	}
+}
+
+// mapStats returns the selected counter of each trial.
+func mapStats(stats []Trial, counter func(Trial) int64) []int64 {
+	var values []int64
+	for _, s := range stats {
+		values = append(values, counter(s))
+	}
+
+	return values
+}
+
+func TestSummarize(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 100}
+	want := summary{Mean: 115.0 / 6, Median: 3.5, P95: 100, Max: 100}
+
+	if got := summarize(values); got != want {
+		t.Errorf("summarize(%v) = %+v, want %+v", values, got, want)
+	}
+	if got := summarize(nil); got != (summary{}) {
+		t.Errorf("summarize(nil) = %+v, want %+v", got, summary{})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	testcases := []struct {
+		values []int64
+		want   []bin
+	}{
+		{nil, nil},
+		{[]int64{7, 7}, []bin{{7, 7, 2}}},
+		{[]int64{1, 3, 2, 1}, []bin{{1, 1, 2}, {2, 2, 1}, {3, 3, 1}}},
+		{[]int64{0, 25, 5}, []bin{{0, 2, 1}, {3, 5, 1}, {6, 8, 0}, {9, 11, 0}, {12, 14, 0}, {15, 17, 0}, {18, 20, 0}, {21, 23, 0}, {24, 26, 1}}},
+	}
+	for _, tc := range testcases {
+		if got := histogram(tc.values); !slices.Equal(got, tc.want) {
+			t.Errorf("histogram(%v) = %v, want %v", tc.values, got, tc.want)
+		}
+	}
+}
+
+func TestPrintSimulation(t *testing.T) {
+	config := AppConfig{Command: "simulate", Algorithm: lookup("slow"), N: 3, Trials: 2}
+	trials := []Trial{{Stats: sortof.Stats{Comparisons: 3, Calls: 10}}, {Stats: sortof.Stats{Comparisons: 3, Calls: 10}}}
+
+	var stdout, stderr bytes.Buffer
+	if err := PrintSimulation(&stdout, &stderr, config, trials); err != nil {
+		t.Errorf("PrintSimulation(%v, %v) returns error: %v", config, trials, err)
+	}
+	if !strings.Contains(stdout.String(), "comparisons:\n") || stderr.Len() != 0 {
+		t.Errorf("PrintSimulation(%v, %v) prints %q and %q, want ASCII histogram", config, trials, stdout.String(), stderr.String())
+	}
+
+	config.CSV = true
+	stdout.Reset()
+	if err := PrintSimulation(&stdout, &stderr, config, trials); err != nil {
+		t.Errorf("PrintSimulation(%v, %v) returns error: %v", config, trials, err)
+	}
+	if want := "comparisons_from,comparisons_to,trials\n3,3,2\n"; stdout.String() != want {
+		t.Errorf("PrintSimulation(%v, %v) prints %q, want %q", config, trials, stdout.String(), want)
+	}
+	if !strings.HasPrefix(stderr.String(), "trials: 2, n: 3\n") {
+		t.Errorf("PrintSimulation(%v, %v) prints summary %q", config, trials, stderr.String())
+	}
+}
+
+func TestPrintSimulationExpected(t *testing.T) {
+	config := AppConfig{Command: "simulate", Algorithm: lookup("bogo"), N: 3, Trials: 1, LockPrefix: true}
+	trials := []Trial{{Stats: sortof.Stats{Comparisons: 12, Shuffles: 3}}}
+
+	var stdout, stderr bytes.Buffer
+	if err := PrintSimulation(&stdout, &stderr, config, trials); err != nil {
+		t.Errorf("PrintSimulation(%v, %v) returns error: %v", config, trials, err)
+	}
+	for _, want := range []string{
+		fmt.Sprintf("%-12s %12s", "shuffles", "3"),
+		fmt.Sprintf("%-12s %12s", "comparisons", "12"),
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("PrintSimulation(%v, %v) prints %q, want row %q", config, trials, stdout.String(), want)
+		}
+	}
+}
+
+func TestPrintSimulationCensored(t *testing.T) {
+	config := AppConfig{Command: "simulate", Algorithm: lookup("bogo"), N: 3, Trials: 2}
+	trials := []Trial{{Stats: sortof.Stats{Comparisons: 4}}, {Stats: sortof.Stats{Comparisons: 100}, Censored: true}}
+
+	var stdout, stderr bytes.Buffer
+	if err := PrintSimulation(&stdout, &stderr, config, trials); err != nil {
+		t.Errorf("PrintSimulation(%v, %v) returns error: %v", config, trials, err)
+	}
+	for _, want := range []string{"trials: 2, n: 3\ncensored: 1", fmt.Sprintf("%12d - %-12d", 4, 4)} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("PrintSimulation(%v, %v) prints %q, want %q", config, trials, stdout.String(), want)
+		}
+	}
+	if strings.Contains(stdout.String(), "100") {
+		t.Errorf("PrintSimulation(%v, %v) prints %q, want censored trial excluded", config, trials, stdout.String())
+	}
+}
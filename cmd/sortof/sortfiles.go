@@ -3,86 +3,181 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/macie/sortof"
 )
 
-// BogosortFile returns a sorted lines from the file in ascending order.
-// A context controls cancellation.
-func BogosortFile(ctx context.Context, file io.ReadCloser) ([]string, error) {
-	lines := []string{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			return []string{}, context.Cause(ctx)
-		default:
-			lines = append(lines, scanner.Text())
-		}
+// SortFile returns sorted lines from the file in ascending order together
+// with statistics of sorting. Lines are sorted by config.Algorithm with
+// settings from other config fields, which are ignored by algorithms not
+// supporting them. With positive config.CosmicRate the lines are hit by
+// simulated cosmic rays, which swap random lines. A context controls
+// cancellation. With config.BestEffort interrupted sorting returns
+// the partially sorted lines together with a *sortof.PartialSortError. With
+// positive config.RefuseAbove the lines are not sorted if the expected time
+// of sorting is longer. Lines sorted by the sample run of the estimation are
+// not sorted again.
+func SortFile(ctx context.Context, file io.ReadCloser, config AppConfig) ([]string, sortof.Stats, error) {
+	var stats sortof.Stats
+	lines, err := readLines(ctx, file)
+	if err != nil {
+		return []string{}, stats, err
 	}
-	if err := scanner.Err(); err != nil {
-		return []string{}, err
+	if config.RefuseAbove > 0 {
+		c, err := calibrate(ctx, lines, config)
+		if err != nil {
+			return []string{}, stats, err
+		}
+		if c.finished && withinLimits(c.stats, config) {
+			return c.sorted, c.stats, nil
+		}
+		_, expected, err := expect(lines, c, config)
+		if err != nil {
+			return []string{}, stats, err
+		}
+		if expected > config.RefuseAbove.Seconds() {
+			return []string{}, stats, fmt.Errorf("expected time of sorting (%s) exceeds %v, which is set by -refuse-above", formatSeconds(expected), config.RefuseAbove)
+		}
 	}
 
-	if err := sortof.Bogosort(ctx, lines); err != nil {
-		return []string{}, err
+	opts := sortOptions(config, &stats)
+	var n int
+	if config.CosmicRate > 0 {
+		n, err = sortUnderCosmicRays(ctx, lines, config, opts)
+	} else {
+		n, err = config.Algorithm.Sort(ctx, sortof.WrapSlice(lines, config.Compare), opts...)
+	}
+	var partial *sortof.PartialSortError
+	if err != nil && !(config.BestEffort && errors.As(err, &partial)) {
+		return []string{}, stats, err
 	}
 
-	return lines, nil
+	return lines[:n], stats, err
 }
 
-// MiraclesortFile returns a sorted lines from the file in ascending order.
-// A context controls cancellation.
-func MiraclesortFile(ctx context.Context, file io.ReadCloser) ([]string, error) {
-	lines := []string{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			return []string{}, context.Cause(ctx)
-		default:
-			lines = append(lines, scanner.Text())
+// sortUnderCosmicRays sorts lines by config.Algorithm with options opts while
+// they are hit by cosmic rays with config.CosmicRate. Rays can hit the lines
+// after the algorithm found them sorted, so the order is checked again after
+// the rays are stopped, and sorting is repeated when it is broken.
+func sortUnderCosmicRays(ctx context.Context, lines []string, config AppConfig, opts []sortof.Option) (int, error) {
+	var mu sync.Mutex
+	rays := sortof.NewCosmicRays(lines, &mu, config.CosmicRate, sortof.WithSeed(config.Seed))
+	opts = append(slices.Clip(opts), sortof.WithLocker(&mu), sortof.WithNotify(rays.Notify()))
+	for {
+		raysCtx, stopRays := context.WithCancel(ctx)
+		stopped := make(chan error)
+		go func() { stopped <- rays.Run(raysCtx) }()
+
+		n, err := config.Algorithm.Sort(ctx, sortof.WrapSlice(lines, config.Compare), opts...)
+		stopRays()
+		<-stopped // lines cannot be modified after return
+		if err != nil || slices.IsSortedFunc(lines[:n], config.Compare) {
+			return n, err
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return []string{}, err
-	}
+}
 
-	if err := sortof.Miraclesort(ctx, lines); err != nil {
-		return []string{}, err
+// sortOptions returns options of sorting algorithms set by config, which
+// record statistics of sorting in stats.
+func sortOptions(config AppConfig, stats *sortof.Stats) []sortof.Option {
+	opts := []sortof.Option{sortof.WithSeed(config.Seed), sortof.WithStats(stats)}
+	if config.Stable {
+		opts = append(opts, sortof.WithStable())
+	}
+	if config.BestEffort {
+		opts = append(opts, sortof.WithBestEffort())
+	}
+	if config.LockPrefix {
+		opts = append(opts, sortof.WithLockedPrefix())
+	}
+	if config.Jobs > 0 {
+		opts = append(opts, sortof.WithWorkers(config.Jobs))
+	}
+	if config.MaxShuffles > 0 || config.MaxComparisons > 0 {
+		opts = append(opts, sortof.WithBudget(sortof.Budget{
+			Shuffles:    config.MaxShuffles,
+			Comparisons: config.MaxComparisons,
+		}))
+	}
+	if config.FallbackAfter > 0 {
+		opts = append(opts, sortof.WithFallback(config.FallbackAfter, sortof.Budget{}))
 	}
 
-	return lines, nil
+	return opts
 }
 
-// SlowsortFile returns a sorted lines from the file in ascending order.
-// A context controls cancellation.
-func SlowsortFile(ctx context.Context, file io.ReadCloser) ([]string, error) {
-	lines := []string{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+// MiraclesortWatch waits until someone (or something) sorts lines of
+// the file with the given name and returns them. The file is checked every
+// watchInterval, and it is re-read from disk only after its modification time
+// and size stayed the same for a whole interval, so a file in the middle of
+// rewriting is never accepted. A context controls cancellation.
+func MiraclesortWatch(ctx context.Context, name string, config AppConfig) ([]string, error) {
+	var last os.FileInfo
+	checked := false
+	for {
+		info, err := os.Stat(name)
+		if err != nil {
+			return []string{}, err
+		}
+
+		switch {
+		case last == nil || !sameStat(info, last):
+			last = info
+			checked = false
+		case !checked:
+			checked = true
+			lines, err := readLinesFrom(ctx, name)
+			if err != nil {
+				return []string{}, err
+			}
+			after, err := os.Stat(name)
+			if err != nil {
+				return []string{}, err
+			}
+			if !sameStat(after, last) {
+				// changed during reading
+				last = after
+				checked = false
+				break
+			}
+			if slices.IsSortedFunc(lines, config.Compare) {
+				return lines, nil
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			return []string{}, context.Cause(ctx)
-		default:
-			lines = append(lines, scanner.Text())
+		case <-time.After(watchInterval):
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return []string{}, err
-	}
+}
+
+// sameStat reports whether file infos have the same modification time and size.
+func sameStat(a, b os.FileInfo) bool {
+	return a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
 
-	if err := sortof.Slowsort(ctx, lines); err != nil {
+// readLinesFrom returns lines of the file with the given name.
+func readLinesFrom(ctx context.Context, name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
 		return []string{}, err
 	}
+	defer f.Close()
 
-	return lines, nil
+	return readLines(ctx, f)
 }
 
-// StalinsortFile returns a sorted lines from the file in ascending order.
-// A context controls cancellation.
-func StalinsortFile(ctx context.Context, file io.ReadCloser) ([]string, error) {
+// readLines returns lines of the file.
+func readLines(ctx context.Context, file io.Reader) ([]string, error) {
 	lines := []string{}
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -97,10 +192,5 @@ func StalinsortFile(ctx context.Context, file io.ReadCloser) ([]string, error) {
 		return []string{}, err
 	}
 
-	sorted, err := sortof.Stalinsort(ctx, lines)
-	if err != nil {
-		return []string{}, err
-	}
-
-	return sorted, nil
+	return lines, nil
 }
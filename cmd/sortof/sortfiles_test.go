@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiraclesortWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	name := filepath.Join(t.TempDir(), "watched")
+	if err := os.WriteFile(name, []byte("c\na\nb\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(2 * watchInterval)
+		os.WriteFile(name, []byte("a\nb\nc\nd\n"), 0o600)
+	}()
+
+	got, err := MiraclesortWatch(ctx, name, AppConfig{})
+	if err != nil {
+		t.Fatalf("MiraclesortWatch(%v, %q, AppConfig{}) returns error: %v", ctx, name, err)
+	}
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("MiraclesortWatch(%v, %q, AppConfig{}) = %v, want %v", ctx, name, got, want)
+	}
+}
+
+func TestMiraclesortWatchRewritten(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	name := filepath.Join(t.TempDir(), "watched")
+	if err := os.WriteFile(name, []byte("c\na\nb\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		// empty file in the middle of rewriting is sorted, but incomplete
+		time.Sleep(2*watchInterval + 3*watchInterval/4)
+		os.WriteFile(name, nil, 0o600)
+		time.Sleep(watchInterval / 2)
+		os.WriteFile(name, []byte("a\nb\nc\nd\n"), 0o600)
+	}()
+
+	got, err := MiraclesortWatch(ctx, name, AppConfig{})
+	if err != nil {
+		t.Fatalf("MiraclesortWatch(%v, %q, AppConfig{}) returns error: %v", ctx, name, err)
+	}
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("MiraclesortWatch(%v, %q, AppConfig{}) = %v, want %v", ctx, name, got, want)
+	}
+}
+
+func TestMiraclesortWatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), watchInterval/2)
+	defer cancel()
+	name := filepath.Join(t.TempDir(), "watched")
+	if err := os.WriteFile(name, []byte("b\na\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := MiraclesortWatch(ctx, name, AppConfig{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("MiraclesortWatch(%v, %q, AppConfig{}) returns error: %v, want %v", ctx, name, err, context.DeadlineExceeded)
+	}
+}
+
+func TestSortFileCosmicRays(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	input := "b\na\n"
+	config := AppConfig{Algorithm: lookup("miracle"), CosmicRate: 1e9, Seed: 1}
+
+	// rays hit lines much more often than miracles are checked, so they can
+	// easily break the order found by the algorithm
+	for i := 0; i < 20; i++ {
+		got, _, err := SortFile(ctx, nopCloser{strings.NewReader(input)}, config)
+		if err != nil {
+			t.Fatalf("SortFile(%v, %q, %v) returns error: %v", ctx, input, config, err)
+		}
+		if !slices.IsSorted(got) {
+			t.Fatalf("SortFile(%v, %q, %v) cannot sort; got %v", ctx, input, config, got)
+		}
+	}
+}
+
+func TestSortFileCosmicRaysFallback(t *testing.T) {
+	// run with -race: the fallback moves lines while rays still hit them
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	input := "e\nd\nc\nb\na\n"
+	config := AppConfig{Algorithm: lookup("miracle"), CosmicRate: 1e5, FallbackAfter: time.Millisecond, Seed: 1}
+
+	for i := 0; i < 20; i++ {
+		got, _, err := SortFile(ctx, nopCloser{strings.NewReader(input)}, config)
+		if err != nil {
+			t.Fatalf("SortFile(%v, %q, %v) returns error: %v", ctx, input, config, err)
+		}
+		if !slices.IsSorted(got) {
+			t.Fatalf("SortFile(%v, %q, %v) cannot sort; got %v", ctx, input, config, got)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package sortof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInconsistentComparator is returned when the comparison function is not
+// a consistent ordering of elements.
+var ErrInconsistentComparator = errors.New("inconsistent comparator")
+
+// ComparatorError describes elements for which the comparison function breaks
+// the rules of strict weak ordering. It wraps ErrInconsistentComparator.
+type ComparatorError struct {
+	Reason   string
+	Elements []any
+}
+
+// Error returns a description of the inconsistency.
+func (e *ComparatorError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInconsistentComparator, e.Reason)
+}
+
+// Unwrap returns ErrInconsistentComparator.
+func (e *ComparatorError) Unwrap() error {
+	return ErrInconsistentComparator
+}
+
+// CheckComparator returns a copy of ctx and the cmp function wrapped with
+// consistency checks. The wrapper verifies that every call of cmp returns -1,
+// 0 or 1, that cmp(a, a) == 0, that cmp(a, b) is opposite to cmp(b, a) and
+// that results are transitive for the compared elements and each of
+// the elements compared just before them. When a check fails, the returned
+// context is cancelled with a *ComparatorError cause, so any sorting function
+// from the package stops and returns it.
+//
+// Checks multiply the number of comparisons, and they cannot prove that cmp
+// is consistent: inconsistencies are detected only among elements compared
+// during sorting. Calling cancel releases resources associated with
+// the returned context.
+func CheckComparator[E any](ctx context.Context, cmp func(a, b E) int) (checkedCtx context.Context, checkedCmp func(a, b E) int, cancel context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	c := &comparatorChecker[E]{cmp: cmp, fail: cancelCause}
+
+	return ctx, c.Compare, func() { cancelCause(context.Canceled) }
+}
+
+// comparatorChecker verifies results of comparison function.
+type comparatorChecker[E any] struct {
+	mu      sync.Mutex
+	cmp     func(a, b E) int
+	fail    context.CancelCauseFunc
+	last    [2]E
+	hasLast bool
+}
+
+// Compare returns cmp(a, b) and verifies its consistency.
+func (c *comparatorChecker[E]) Compare(a, b E) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := c.cmp(a, b)
+	if err := c.check(a, b, result); err != nil {
+		c.fail(err)
+	}
+	c.last, c.hasLast = [2]E{a, b}, true
+
+	return result
+}
+
+// check returns a *ComparatorError if the result of cmp(a, b) is inconsistent.
+func (c *comparatorChecker[E]) check(a, b E, result int) error {
+	if result < -1 || result > 1 {
+		return &ComparatorError{
+			Reason:   fmt.Sprintf("cmp(%v, %v) = %d is not normalized to -1, 0 or 1", a, b, result),
+			Elements: []any{a, b},
+		}
+	}
+	if r := c.cmp(a, a); r != 0 {
+		return &ComparatorError{
+			Reason:   fmt.Sprintf("cmp(%v, %v) = %d is not reflexive", a, a, r),
+			Elements: []any{a},
+		}
+	}
+	if r := c.cmp(b, a); sign(r) != -result {
+		return &ComparatorError{
+			Reason:   fmt.Sprintf("cmp(%v, %v) = %d and cmp(%v, %v) = %d are not antisymmetric", a, b, result, b, a, r),
+			Elements: []any{a, b},
+		}
+	}
+	if !c.hasLast {
+		return nil
+	}
+
+	for _, u := range c.last {
+		ua, ub := sign(c.cmp(u, a)), sign(c.cmp(u, b))
+		if !isTransitive(ua, result, ub) {
+			return &ComparatorError{
+				Reason: fmt.Sprintf("cmp(%v, %v) = %d, cmp(%v, %v) = %d and cmp(%v, %v) = %d are not transitive",
+					u, a, ua, a, b, result, u, b, ub),
+				Elements: []any{u, a, b},
+			}
+		}
+	}
+
+	return nil
+}
+
+// inconsistency returns the cause of cancellation of ctx if it is
+// an inconsistency of the comparison function detected by CheckComparator.
+// Sorting which finishes despite cancellation of ctx reports only it.
+func inconsistency(ctx context.Context) error {
+	if err := context.Cause(ctx); errors.Is(err, ErrInconsistentComparator) {
+		return err
+	}
+
+	return nil
+}
+
+// isTransitive reports whether signs of comparisons xy, yz and xz of three
+// elements x, y, z are consistent with strict weak ordering.
+func isTransitive(xy, yz, xz int) bool {
+	switch {
+	case xy == yz:
+		return xz == xy
+	case xy == 0:
+		return xz == yz
+	case yz == 0:
+		return xz == xy
+	}
+
+	return true // x < y > z and x > y < z give no information about x and z
+}
+
+// sign returns -1, 0 or 1 depending on the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
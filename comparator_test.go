@@ -0,0 +1,209 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheckComparatorConsistent(t *testing.T) {
+	testcases := [][]int{
+		{},
+		{1, 2, 3},
+		{3, 1, 2, 1},
+		{5, 4, 3, 2, 1},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+			ctx, cmpInts, cancel := CheckComparator(context.Background(), cmp.Compare[int])
+			defer cancel()
+
+			collection := slices.Clone(tc)
+			if err := BogosortFunc(ctx, collection, cmpInts); err != nil {
+				t.Errorf("BogosortFunc(%v, %v, checked cmp.Compare) returns error: %v", ctx, tc, err)
+			}
+			collection = slices.Clone(tc)
+			if err := SlowsortFunc(ctx, collection, cmpInts); err != nil {
+				t.Errorf("SlowsortFunc(%v, %v, checked cmp.Compare) returns error: %v", ctx, tc, err)
+			}
+			if _, err := StalinsortFunc(ctx, tc, cmpInts); err != nil {
+				t.Errorf("StalinsortFunc(%v, %v, checked cmp.Compare) returns error: %v", ctx, tc, err)
+			}
+			if err := MiraclesortFunc(ctx, collection, cmpInts); err != nil {
+				t.Errorf("MiraclesortFunc(%v, %v, checked cmp.Compare) returns error: %v", ctx, collection, err)
+			}
+		})
+	}
+}
+
+func TestCheckComparatorInconsistent(t *testing.T) {
+	// rock < paper < scissors < rock
+	cmpHands := func(a, b string) int {
+		beats := map[string]string{"rock": "scissors", "paper": "rock", "scissors": "paper"}
+		switch {
+		case a == b:
+			return 0
+		case beats[a] == b:
+			return 1
+		}
+		return -1
+	}
+	// returns difference instead of -1, 0 or 1
+	cmpSubtract := func(a, b string) int { return len(a) - len(b) }
+	// never reports equality
+	cmpNeverEqual := func(a, b string) int {
+		if a < b {
+			return -1
+		}
+		return 1
+	}
+	// ignores arguments order
+	cmpAsymmetric := func(a, b string) int {
+		if a == b {
+			return 0
+		}
+		return -1
+	}
+	testcases := map[string]struct {
+		cmp func(a, b string) int
+		x   []string
+	}{
+		"non-transitive": {cmpHands, []string{"scissors", "paper", "rock"}},
+		"non-normalized": {cmpSubtract, []string{"ccc", "a", "bb"}},
+		"non-reflexive":  {cmpNeverEqual, []string{"c", "a", "b"}},
+		"asymmetric":     {cmpAsymmetric, []string{"c", "a", "b"}},
+	}
+	sorters := map[string]func(ctx context.Context, x []string, cmp func(a, b string) int) error{
+		"BogosortFunc": BogosortFunc[[]string],
+		"SlowsortFunc": SlowsortFunc[[]string],
+		"StalinsortFunc": func(ctx context.Context, x []string, cmp func(a, b string) int) error {
+			_, err := StalinsortFunc(ctx, x, cmp)
+			return err
+		},
+	}
+	for name, tc := range testcases {
+		for sorterName, sorter := range sorters {
+			name, tc, sorterName, sorter := name, tc, sorterName, sorter
+			t.Run(name+"/"+sorterName, func(t *testing.T) {
+				t.Parallel()
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				ctx, checkedCmp, cancelCheck := CheckComparator(ctx, tc.cmp)
+				defer cancelCheck()
+
+				err := sorter(ctx, slices.Clone(tc.x), checkedCmp)
+				if !errors.Is(err, ErrInconsistentComparator) {
+					t.Fatalf("%s(%v, %v, checked %s) returns error: %v, want %v", sorterName, ctx, tc.x, name, err, ErrInconsistentComparator)
+				}
+				var cmpErr *ComparatorError
+				if !errors.As(err, &cmpErr) || len(cmpErr.Elements) == 0 {
+					t.Errorf("%s(%v, %v, checked %s) returns error without offending elements: %v", sorterName, ctx, tc.x, name, err)
+				}
+			})
+		}
+	}
+}
+
+func TestNonNormalizedComparator(t *testing.T) {
+	ctx := context.Background()
+	cmpSubtract := func(a, b int) int { return 5 * (a - b) }
+	tc := []int{3, 1, 4, 1, 5, 9, 2, 6}
+
+	collection := slices.Clone(tc)
+	if err := SlowsortFunc(ctx, collection, cmpSubtract); err != nil {
+		t.Errorf("SlowsortFunc(%v, %v, cmpSubtract) returns error: %v", ctx, tc, err)
+	}
+	if !slices.IsSorted(collection) {
+		t.Errorf("SlowsortFunc(%v, %v, cmpSubtract) cannot sort; got %v", ctx, tc, collection)
+	}
+
+	got, err := StalinsortFunc(ctx, tc, cmpSubtract)
+	if err != nil {
+		t.Errorf("StalinsortFunc(%v, %v, cmpSubtract) returns error: %v", ctx, tc, err)
+	}
+	if want := []int{3, 4, 5, 9}; !slices.Equal(got, want) {
+		t.Errorf("StalinsortFunc(%v, %v, cmpSubtract) = %v, want %v", ctx, tc, got, want)
+	}
+}
+
+func TestWithComparatorCheck(t *testing.T) {
+	ctx := context.Background()
+	// ignores arguments order
+	cmpAsymmetric := func(a, b int) int {
+		if a == b {
+			return 0
+		}
+		return -1
+	}
+	tc := []int{3, 1, 2}
+	sorters := map[string]func(x []int) error{
+		"BogosortWith": func(x []int) error {
+			return BogosortWith(ctx, x, cmpAsymmetric, WithComparatorCheck())
+		},
+		"SlowsortWith": func(x []int) error {
+			return SlowsortWith(ctx, x, cmpAsymmetric, WithComparatorCheck(), WithStable())
+		},
+		"StalinsortWith": func(x []int) error {
+			_, err := StalinsortWith(ctx, x, cmpAsymmetric, WithComparatorCheck())
+			return err
+		},
+	}
+	for name, sorter := range sorters {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if err := sorter(slices.Clone(tc)); !errors.Is(err, ErrInconsistentComparator) {
+				t.Errorf("%s(%v, %v, cmpAsymmetric, WithComparatorCheck()) returns error: %v, want %v", name, ctx, tc, err, ErrInconsistentComparator)
+			}
+		})
+	}
+}
+
+func TestSortFinishedWhenCancelled(t *testing.T) {
+	tc := []int{1, 2}
+	sorters := map[string]func(ctx context.Context, x []int, cmp func(a, b int) int) error{
+		"BogosortWith": func(ctx context.Context, x []int, cmp func(a, b int) int) error {
+			return BogosortWith(ctx, x, cmp)
+		},
+		"BogosortWith/workers": func(ctx context.Context, x []int, cmp func(a, b int) int) error {
+			return BogosortWith(ctx, x, cmp, WithWorkers(2))
+		},
+		"MiraclesortWith": func(ctx context.Context, x []int, cmp func(a, b int) int) error {
+			return MiraclesortWith(ctx, x, cmp)
+		},
+		"StalinsortWith": func(ctx context.Context, x []int, cmp func(a, b int) int) error {
+			got, err := StalinsortWith(ctx, x, cmp)
+			if err == nil && !slices.Equal(got, x) {
+				return fmt.Errorf("got %v, want %v", got, x)
+			}
+			return err
+		},
+	}
+	for name, sorter := range sorters {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// the context is cancelled during the last comparison
+			cmpCancelling := func(a, b int) int {
+				cancel()
+				return cmp.Compare(a, b)
+			}
+
+			collection := slices.Clone(tc)
+			if err := sorter(ctx, collection, cmpCancelling); err != nil {
+				t.Errorf("%s(%v, %v, cmpCancelling) returns error: %v", name, ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				t.Errorf("%s(%v, %v, cmpCancelling) cannot sort; got %v", name, ctx, tc, collection)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package sortof_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/macie/sortof"
+	"github.com/macie/sortof/sortoftest"
+)
+
+func TestConformance(t *testing.T) {
+	for _, a := range sortof.Algorithms() {
+		a := a
+		t.Run(a.Name, func(t *testing.T) {
+			t.Parallel()
+			if a.Name == "miracle" {
+				// miracles are too rare for tests
+				a = withMiracle(a)
+			}
+
+			sortoftest.RunConformance(t, a)
+		})
+	}
+}
+
+// withMiracle returns the algorithm a, which data is sorted by a miracle as
+// soon as a starts waiting for it.
+func withMiracle(a sortof.Algorithm) sortof.Algorithm {
+	sort := a.Sort
+	a.Sort = func(ctx context.Context, data sortof.Interface, opts ...sortof.Option) (int, error) {
+		return sort(ctx, data, append(opts, sortof.WithClock(miracleClock{ctx, data}))...)
+	}
+
+	return a
+}
+
+// miracleClock is a sortof.Clock which sorts data with insertion sort when
+// Miraclesort starts waiting, and then ticks immediately. With cancelled
+// context it never ticks, so sorting stops as usual.
+type miracleClock struct {
+	ctx  context.Context
+	data sortof.Interface
+}
+
+func (c miracleClock) After(time.Duration) <-chan time.Time {
+	if c.ctx.Err() != nil {
+		return nil
+	}
+
+	for i := 1; i < c.data.Len(); i++ {
+		for j := i; j > 0 && c.data.Compare(j, j-1) < 0; j-- {
+			c.data.Swap(j, j-1)
+		}
+	}
+	tick := make(chan time.Time, 1)
+	tick <- time.Now()
+
+	return tick
+}
@@ -0,0 +1,103 @@
+package sortof
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// CosmicRays is a simulated source of miracles for Miraclesort. It hits
+// the watched slice at random moments with the given average rate, and each
+// hit modifies the slice while holding the lock. Use the same lock with
+// MiraclesortLocked to avoid data races.
+type CosmicRays struct {
+	rate   float64
+	mu     sync.Locker
+	hit    func(r *rand.Rand)
+	notify chan struct{}
+	clock  Clock
+	random *rand.Rand
+}
+
+// integer is a constraint for types which can be hit by bit flips.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// NewCosmicRays returns CosmicRays which swap two random elements of x about
+// rate times per second. Applicable options are WithClock and WithSeed.
+func NewCosmicRays[S ~[]E, E any](x S, mu sync.Locker, rate float64, opts ...Option) *CosmicRays {
+	return newCosmicRays(mu, rate, newOptions(opts), func(r *rand.Rand) {
+		if len(x) < 2 {
+			return
+		}
+		i, j := r.Intn(len(x)), r.Intn(len(x))
+		x[i], x[j] = x[j], x[i]
+	})
+}
+
+// NewCosmicBitFlips returns CosmicRays which flip a random bit of a random
+// element of x about rate times per second. Applicable options are WithClock
+// and WithSeed.
+func NewCosmicBitFlips[S ~[]E, E integer](x S, mu sync.Locker, rate float64, opts ...Option) *CosmicRays {
+	var zero E
+	size := int(unsafe.Sizeof(zero)) * 8
+
+	return newCosmicRays(mu, rate, newOptions(opts), func(r *rand.Rand) {
+		if len(x) == 0 {
+			return
+		}
+		x[r.Intn(len(x))] ^= E(1) << r.Intn(size)
+	})
+}
+
+// newCosmicRays returns CosmicRays which call hit function.
+func newCosmicRays(mu sync.Locker, rate float64, o options, hit func(r *rand.Rand)) *CosmicRays {
+	return &CosmicRays{
+		rate:   rate,
+		mu:     mu,
+		hit:    hit,
+		notify: make(chan struct{}, 1),
+		clock:  o.clock,
+		random: o.random(),
+	}
+}
+
+// Run hits the slice until the context is cancelled and returns
+// context.Cause(ctx). Intervals between hits are exponentially distributed,
+// so hits form a Poisson process. Non-positive rate means no hits. Random
+// numbers continue between runs, so Run must not be called concurrently.
+func (c *CosmicRays) Run(ctx context.Context) error {
+	r := c.random
+	for {
+		var hit <-chan time.Time
+		if c.rate > 0 {
+			interval := time.Duration(r.ExpFloat64() / c.rate * float64(time.Second))
+			hit = c.clock.After(interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-hit:
+			c.mu.Lock()
+			c.hit(r)
+			c.mu.Unlock()
+
+			// notification is dropped when previous one is still pending
+			select {
+			case c.notify <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Notify returns a channel which receives a value after hits. It can be
+// passed to Miraclesort with the WithNotify option.
+func (c *CosmicRays) Notify() <-chan struct{} {
+	return c.notify
+}
@@ -0,0 +1,111 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCosmicRaysSwap(t *testing.T) {
+	testcases := [][]string{
+		{"b", "a"},
+		{"c", "a", "b"},
+		{"d", "c", "b", "a"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			var mu sync.Mutex
+			collection := slices.Clone(tc)
+			rays := NewCosmicRays(collection, &mu, 10000)
+
+			raysCtx, stopRays := context.WithCancel(ctx)
+			stopped := make(chan error)
+			go func() { stopped <- rays.Run(raysCtx) }()
+
+			err := MiraclesortLocked(ctx, collection, cmp.Compare[string], &mu, WithNotify(rays.Notify()), WithPollInterval(time.Millisecond))
+			stopRays()
+			<-stopped
+			if err != nil {
+				t.Fatalf("MiraclesortLocked(%v, %v, cmp.Compare, &mu) with cosmic rays returns error: %v", ctx, tc, err)
+			}
+			if len(collection) != len(tc) {
+				t.Errorf("cosmic rays change length of %v to %v", tc, collection)
+			}
+		})
+	}
+}
+
+func TestCosmicRaysBitFlip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var mu sync.Mutex
+	collection := []uint8{255, 0}
+	rays := NewCosmicBitFlips(collection, &mu, 10000)
+
+	raysCtx, stopRays := context.WithCancel(ctx)
+	stopped := make(chan error)
+	go func() { stopped <- rays.Run(raysCtx) }()
+
+	err := MiraclesortLocked(ctx, collection, cmp.Compare[uint8], &mu, WithNotify(rays.Notify()), WithPollInterval(time.Millisecond))
+	stopRays()
+	<-stopped
+	if err != nil {
+		t.Errorf("MiraclesortLocked(%v, [255 0], cmp.Compare, &mu) with cosmic bit flips returns error: %v", ctx, err)
+	}
+}
+
+func TestCosmicRaysNoHits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var mu sync.Mutex
+	tc := []int{3, 2, 1}
+	collection := slices.Clone(tc)
+
+	err := NewCosmicRays(collection, &mu, 0).Run(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("NewCosmicRays(%v, &mu, 0).Run(%v) returns error: %v, want %v", tc, ctx, err, context.DeadlineExceeded)
+	}
+	if !slices.Equal(collection, tc) {
+		t.Errorf("NewCosmicRays(%v, &mu, 0).Run(%v) modifies slice to %v", tc, ctx, collection)
+	}
+}
+
+func TestCosmicRaysSeed(t *testing.T) {
+	tc := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	hit := func(seed int64) []int {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var mu sync.Mutex
+		collection := slices.Clone(tc)
+		clock := newFakeClock()
+		rays := NewCosmicRays(collection, &mu, 1, WithClock(clock), WithSeed(seed))
+		stopped := make(chan error)
+		go func() { stopped <- rays.Run(ctx) }()
+
+		for i := 0; i < 20; i++ {
+			<-clock.waiting
+			clock.ticks <- time.Now()
+		}
+		<-clock.waiting
+		cancel()
+		<-stopped
+
+		return collection
+	}
+
+	got, want := hit(1), hit(1)
+	if !slices.Equal(got, want) {
+		t.Errorf("NewCosmicRays(%v, &mu, 1, WithSeed(1)) hits slice to %v and %v, want the same result", tc, got, want)
+	}
+	if slices.Equal(got, tc) {
+		t.Errorf("NewCosmicRays(%v, &mu, 1, WithSeed(1)) does not modify slice", tc)
+	}
+}
@@ -0,0 +1,171 @@
+package sortof
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNoEstimate is returned by Estimate for algorithms without known
+// formulas of their work.
+var ErrNoEstimate = errors.New("no estimate")
+
+// Estimation is the expected work of sorting a slice in random order (see
+// Estimate). Counters are floating-point numbers, because for pessimal
+// algorithms they overflow integers already for short slices. Infinite
+// counter means that sorting is not expected to finish.
+type Estimation struct {
+	Comparisons float64 // calls of the comparison function
+	Swaps       float64 // exchanges of two elements
+	Shuffles    float64 // random permutations of the slice (Bogosort)
+	Calls       float64 // recursive calls (Slowsort)
+	Sleeps      float64 // waits for a miracle (Miraclesort)
+}
+
+// String returns the summary of all counters.
+func (e Estimation) String() string {
+	return fmt.Sprintf("comparisons: %.4g, swaps: %.4g, shuffles: %.4g, calls: %.4g, sleeps: %.4g",
+		e.Comparisons, e.Swaps, e.Shuffles, e.Calls, e.Sleeps)
+}
+
+// Estimate returns the expected work of sorting n elements in random order
+// with the algorithm a and settings from opts. Slice duplicates contains
+// sizes of groups of equal elements (groups of a single element can be
+// omitted), because equal elements make more permutations sorted. Options
+// which change the expected work are WithLockedPrefix and WithStable. Other
+// options are ignored, e.g. concurrent workers (see WithWorkers) share
+// the same work. The counters are comparable with Stats, so the expected time
+// of sorting can be calibrated with a short measured run.
+func Estimate(a Algorithm, n int, duplicates []int, opts ...Option) (Estimation, error) {
+	if a.Estimate == nil {
+		return Estimation{}, fmt.Errorf("%w for algorithm %q", ErrNoEstimate, a.Name)
+	}
+	if n < 0 {
+		return Estimation{}, fmt.Errorf("cannot estimate sorting of %d elements", n)
+	}
+	total := 0
+	for _, m := range duplicates {
+		if m < 1 {
+			return Estimation{}, fmt.Errorf("invalid size %d of group of equal elements", m)
+		}
+		total += m
+	}
+	if total > n {
+		return Estimation{}, fmt.Errorf("groups of equal elements contain %d elements, want at most %d", total, n)
+	}
+
+	return a.Estimate(n, duplicates, opts...), nil
+}
+
+// sortedProbability returns the probability that a random permutation of
+// n elements with groups of equal elements of the given sizes is sorted.
+func sortedProbability(n int, duplicates []int) float64 {
+	lg, _ := math.Lgamma(float64(n + 1))
+	for _, m := range duplicates {
+		lgm, _ := math.Lgamma(float64(m + 1))
+		lg -= lgm
+	}
+
+	return math.Exp(-lg)
+}
+
+// bogosortEstimate returns the expected work of Bogosort. Each check of
+// a random permutation is successful with probability p, so there are 1/p
+// checks on average. The check stops at the first pair in the wrong order,
+// and the first k elements of a permutation of distinct elements are sorted
+// with probability 1/k!. Stable sorting accepts only one permutation, as if
+// all elements were distinct.
+func bogosortEstimate(n int, duplicates []int, opts ...Option) Estimation {
+	o := newOptions(opts)
+	if n < 2 {
+		return Estimation{}
+	}
+	if o.stable {
+		duplicates = nil
+	}
+	if o.lockPrefix {
+		return lockedPrefixEstimate(n)
+	}
+
+	checks := 1 / sortedProbability(n, duplicates)
+	perCheck, factorial := 0.0, 1.0
+	for k := 1; k < n; k++ {
+		factorial *= float64(k)
+		perCheck += 1 / factorial
+	}
+
+	return Estimation{
+		Comparisons: checks * perCheck,
+		Swaps:       (checks - 1) * float64(n-1),
+		Shuffles:    checks - 1,
+	}
+}
+
+// lockedPrefixEstimate returns the expected work of Bogosort which locks
+// the sorted prefix (see BogosortLockedPrefixFunc) of n distinct elements.
+// Equal elements are locked more easily, so for them it is an upper bound.
+//
+// Unlocked elements are the m greatest ones in random order, and at least k
+// of them are locked with probability (m-k)!/m!. Each check compares m-1
+// pairs to find minima of suffixes and k+1 pairs to find the prefix, and then
+// the remaining m-k elements are shuffled, unless all of them are locked.
+func lockedPrefixEstimate(n int) Estimation {
+	w := make([]Estimation, n+1) // work with m unlocked elements
+	for m := 2; m <= n; m++ {
+		var e Estimation
+		atLeast := 1.0 // probability of locking at least k elements
+		for k := 0; k <= m-2; k++ {
+			next := atLeast / float64(m-k)
+			p := atLeast - next
+			atLeast = next
+
+			e.Comparisons += p * (float64(m+k) + w[m-k].Comparisons)
+			e.Swaps += p * (float64(m-k-1) + w[m-k].Swaps)
+			e.Shuffles += p * (1 + w[m-k].Shuffles)
+		}
+		// locking m-1 elements locks the last one too
+		e.Comparisons += atLeast * float64(2*(m-1))
+
+		// w[m] was zero above, but no element is locked with probability
+		// 1-1/m, so w[m] = e + (1-1/m)*w[m]
+		w[m] = Estimation{
+			Comparisons: e.Comparisons * float64(m),
+			Swaps:       e.Swaps * float64(m),
+			Shuffles:    e.Shuffles * float64(m),
+		}
+	}
+
+	return w[n]
+}
+
+// miraclesortEstimate returns the expected work of Miraclesort, which is
+// infinite unless every permutation is sorted.
+func miraclesortEstimate(n int, duplicates []int, opts ...Option) Estimation {
+	if sortedProbability(n, duplicates) < 1 {
+		return Estimation{Comparisons: math.Inf(1), Sleeps: math.Inf(1)}
+	}
+
+	return Estimation{Comparisons: float64(max(n-1, 0))}
+}
+
+// slowsortEstimate returns the work of Slowsort, which does not depend on
+// the order of elements (except swaps, which are not estimated). Sorting of
+// m elements calls sorting of both halves and of m-1 elements.
+func slowsortEstimate(n int, duplicates []int, opts ...Option) Estimation {
+	calls := make([]float64, max(n+1, 2))
+	comparisons := make([]float64, max(n+1, 2))
+	calls[0], calls[1] = 1, 1
+	for m := 2; m <= n; m++ {
+		left, right := (m+1)/2, m/2
+		calls[m] = 1 + calls[left] + calls[right] + calls[m-1]
+		comparisons[m] = comparisons[left] + comparisons[right] + 1 + comparisons[m-1]
+	}
+
+	return Estimation{Comparisons: comparisons[n], Calls: calls[n]}
+}
+
+// stalinsortEstimate returns the work of Stalinsort, which compares each
+// element with the last kept one.
+func stalinsortEstimate(n int, duplicates []int, opts ...Option) Estimation {
+	return Estimation{Comparisons: float64(max(n-1, 0))}
+}
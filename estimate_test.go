@@ -0,0 +1,132 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	inf := math.Inf(1)
+	testcases := []struct {
+		algorithm  string
+		n          int
+		duplicates []int
+		want       Estimation
+	}{
+		{"bogo", 0, nil, Estimation{}},
+		{"bogo", 1, nil, Estimation{}},
+		{"bogo", 3, nil, Estimation{Comparisons: 9, Swaps: 10, Shuffles: 5}},
+		{"bogo", 3, []int{2}, Estimation{Comparisons: 4.5, Swaps: 4, Shuffles: 2}},
+		{"bogo", 3, []int{3}, Estimation{Comparisons: 1.5}},
+		{"miracle", 1, nil, Estimation{}},
+		{"miracle", 3, []int{3}, Estimation{Comparisons: 2}},
+		{"miracle", 3, []int{2}, Estimation{Comparisons: inf, Sleeps: inf}},
+		{"slow", 0, nil, Estimation{Calls: 1}},
+		{"slow", 3, nil, Estimation{Comparisons: 3, Calls: 10}},
+		{"slow", 3, []int{3}, Estimation{Comparisons: 3, Calls: 10}},
+		{"stalin", 0, nil, Estimation{}},
+		{"stalin", 5, []int{2, 2}, Estimation{Comparisons: 4}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.algorithm, tc.n, tc.duplicates), func(t *testing.T) {
+			t.Parallel()
+			a, _ := Lookup(tc.algorithm)
+
+			got, err := Estimate(a, tc.n, tc.duplicates)
+			if err != nil {
+				t.Errorf("Estimate(%v, %d, %v) returns error: %v", a, tc.n, tc.duplicates, err)
+			}
+			if !approxEqual(got, tc.want) {
+				t.Errorf("Estimate(%v, %d, %v) = %v, want %v", a, tc.n, tc.duplicates, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEstimateOptions(t *testing.T) {
+	testcases := []struct {
+		n          int
+		duplicates []int
+		opts       []Option
+		want       Estimation
+	}{
+		{2, nil, []Option{WithLockedPrefix()}, Estimation{Comparisons: 4, Swaps: 1, Shuffles: 1}},
+		{3, nil, []Option{WithLockedPrefix()}, Estimation{Comparisons: 12, Swaps: 5, Shuffles: 3}},
+		{5, nil, []Option{WithLockedPrefix(), WithWorkers(2)}, Estimation{Comparisons: 48, Swaps: 30, Shuffles: 10}},
+		{3, []int{3}, []Option{WithStable()}, Estimation{Comparisons: 9, Swaps: 10, Shuffles: 5}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.n, tc.duplicates, len(tc.opts)), func(t *testing.T) {
+			t.Parallel()
+			a, _ := Lookup("bogo")
+
+			got, err := Estimate(a, tc.n, tc.duplicates, tc.opts...)
+			if err != nil {
+				t.Errorf("Estimate(%v, %d, %v, opts...) returns error: %v", a, tc.n, tc.duplicates, err)
+			}
+			if !approxEqual(got, tc.want) {
+				t.Errorf("Estimate(%v, %d, %v, opts...) = %v, want %v", a, tc.n, tc.duplicates, got, tc.want)
+			}
+		})
+	}
+}
+
+// approxEqual reports whether all counters of estimations are equal up to
+// rounding errors.
+func approxEqual(a, b Estimation) bool {
+	eq := func(x, y float64) bool { return x == y || math.Abs(x-y) < 1e-9 }
+
+	return eq(a.Comparisons, b.Comparisons) && eq(a.Swaps, b.Swaps) &&
+		eq(a.Shuffles, b.Shuffles) && eq(a.Calls, b.Calls) && eq(a.Sleeps, b.Sleeps)
+}
+
+func TestEstimateSlowsortExact(t *testing.T) {
+	ctx := context.Background()
+	a, _ := Lookup("slow")
+	for n := 0; n < 20; n++ {
+		x := rand.Perm(n)
+		var stats Stats
+
+		if err := SlowsortWith(ctx, x, cmp.Compare[int], WithStats(&stats)); err != nil {
+			t.Fatalf("SlowsortWith(%v, %v, cmp.Compare) returns error: %v", ctx, x, err)
+		}
+		got, _ := Estimate(a, n, nil)
+		if got.Calls != float64(stats.Calls) || got.Comparisons != float64(stats.Comparisons) {
+			t.Errorf("Estimate(%v, %d, nil) = %v, but sorting makes %v", a, n, got, stats)
+		}
+	}
+}
+
+func TestEstimateInvalid(t *testing.T) {
+	bogo, _ := Lookup("bogo")
+	testcases := map[string]struct {
+		algorithm  Algorithm
+		n          int
+		duplicates []int
+	}{
+		"unknown algorithm": {Algorithm{Name: "custom"}, 3, nil},
+		"negative length":   {bogo, -1, nil},
+		"empty group":       {bogo, 3, []int{0}},
+		"too many elements": {bogo, 3, []int{2, 2}},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Estimate(tc.algorithm, tc.n, tc.duplicates); err == nil {
+				t.Errorf("Estimate(%v, %d, %v) returns no error", tc.algorithm, tc.n, tc.duplicates)
+			}
+		})
+	}
+	if _, err := Estimate(Algorithm{Name: "custom"}, 3, nil); !errors.Is(err, ErrNoEstimate) {
+		t.Errorf("Estimate(custom, 3, nil) returns error %v, want %v", err, ErrNoEstimate)
+	}
+}
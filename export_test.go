@@ -0,0 +1,5 @@
+package sortof
+
+// GlobalShuffler is the default Shuffler of sorting functions, exported for
+// tests in package sortof_test.
+var GlobalShuffler Shuffler = globalShuffler{}
@@ -0,0 +1,65 @@
+package sortof
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// WithFallback makes Bogosort, Miraclesort and Slowsort give up after
+// the time limit or when the budget b is exceeded, and sort the slice with
+// slices.SortStableFunc instead, so sorting always finishes eventually and
+// stable algorithms stay stable. Zero time limit or budget means no limit.
+// Stats.Fallbacks reports whether the result was produced by the fallback
+// (see WithStats).
+func WithFallback(after time.Duration, b Budget) Option {
+	return func(o *options) {
+		o.fallback = true
+		o.fallbackAfter = after
+		o.fallbackBudget = b
+	}
+}
+
+// withFallback runs the sort function with limits of the fallback from o.
+// When they are exceeded before ctx is cancelled, x is sorted with
+// slices.SortStableFunc while holding the lock o.locker.
+func withFallback[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options, sort func(ctx context.Context, o options) error) error {
+	return withFallbackFunc(ctx, o, sort, func() {
+		slices.SortStableFunc(x, cmp)
+	})
+}
+
+// withFallbackFunc works like withFallback, but calls the fallback function
+// instead of sorting a slice. The fallback is called while holding the lock
+// o.locker.
+func withFallbackFunc(ctx context.Context, o options, sort func(ctx context.Context, o options) error, fallback func()) error {
+	if !o.fallback {
+		return sort(ctx, o)
+	}
+
+	limitCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(context.Canceled)
+	if o.fallbackBudget != (Budget{}) {
+		o.observers = append(slices.Clip(o.observers), &budgetObserver{limit: o.fallbackBudget, fail: cancel})
+	}
+	if o.fallbackAfter > 0 {
+		var cancelTimeout context.CancelFunc
+		limitCtx, cancelTimeout = context.WithTimeout(limitCtx, o.fallbackAfter)
+		defer cancelTimeout()
+	}
+
+	err := sort(limitCtx, o)
+	if err == nil || ctx.Err() != nil || limitCtx.Err() == nil {
+		return err
+	}
+
+	o.locker.Lock()
+	defer o.locker.Unlock()
+	fallback()
+	if o.stats != nil {
+		atomic.AddInt64(&o.stats.Fallbacks, 1)
+	}
+
+	return nil
+}
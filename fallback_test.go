@@ -0,0 +1,109 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithFallback(t *testing.T) {
+	ctx := context.Background()
+	tc := reversedInts(12)
+	testcases := map[string]func(x []int, opts ...Option) error{
+		"BogosortWith": func(x []int, opts ...Option) error {
+			return BogosortWith(ctx, x, cmp.Compare[int], append(opts, WithFallback(0, Budget{Shuffles: 10}))...)
+		},
+		"BogosortWith/workers": func(x []int, opts ...Option) error {
+			return BogosortWith(ctx, x, cmp.Compare[int], append(opts, WithWorkers(2), WithFallback(time.Millisecond, Budget{}))...)
+		},
+		"SlowsortWith": func(x []int, opts ...Option) error {
+			return SlowsortWith(ctx, x, cmp.Compare[int], append(opts, WithFallback(0, Budget{Calls: 10}))...)
+		},
+		"MiraclesortWith": func(x []int, opts ...Option) error {
+			return MiraclesortWith(ctx, x, cmp.Compare[int], append(opts, WithFallback(time.Millisecond, Budget{}))...)
+		},
+	}
+	for name, sorter := range testcases {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+			var stats Stats
+
+			if err := sorter(collection, WithStats(&stats)); err != nil {
+				t.Errorf("%s(%v, %v, cmp.Compare, WithFallback) returns error: %v", name, ctx, tc, err)
+			}
+			if !slices.IsSorted(collection) {
+				t.Errorf("%s(%v, %v, cmp.Compare, WithFallback) cannot sort; got %v", name, ctx, tc, collection)
+			}
+			if stats.Fallbacks != 1 {
+				t.Errorf("%s(%v, %v, cmp.Compare, WithFallback) records %d fallbacks, want 1", name, ctx, tc, stats.Fallbacks)
+			}
+		})
+	}
+}
+
+func TestWithFallbackStable(t *testing.T) {
+	ctx := context.Background()
+	type element struct{ key, pos int }
+	byKey := func(a, b element) int { return cmp.Compare(a.key, b.key) }
+	tc := make([]element, 100)
+	for i := range tc {
+		tc[i] = element{key: (len(tc) - i) % 3, pos: i}
+	}
+	want := slices.Clone(tc)
+	slices.SortStableFunc(want, byKey)
+	testcases := map[string]func(x []element) error{
+		"BogosortWith": func(x []element) error {
+			return BogosortWith(ctx, x, byKey, WithStable(), WithFallback(0, Budget{Shuffles: 5}))
+		},
+		"SlowsortWith": func(x []element) error {
+			return SlowsortWith(ctx, x, byKey, WithStable(), WithFallback(0, Budget{Calls: 5}))
+		},
+		"MiraclesortWith": func(x []element) error {
+			return MiraclesortWith(ctx, x, byKey, WithFallback(0, Budget{Sleeps: 1}))
+		},
+	}
+	for name, sorter := range testcases {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+
+			if err := sorter(collection); err != nil {
+				t.Errorf("%s(%v, %v, byKey, WithFallback) returns error: %v", name, ctx, tc, err)
+			}
+			if !slices.Equal(collection, want) {
+				t.Errorf("%s(%v, %v, byKey, WithFallback) is not stable; got %v, want %v", name, ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestWithFallbackNotNeeded(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 2}
+	collection := slices.Clone(tc)
+	var stats Stats
+
+	if err := SlowsortWith(ctx, collection, cmp.Compare[int], WithStats(&stats), WithFallback(time.Hour, Budget{Calls: 1000})); err != nil {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithFallback) returns error: %v", ctx, tc, err)
+	}
+	if stats.Fallbacks != 0 || stats.Calls == 0 {
+		t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithFallback) records %v, want result without fallback", ctx, tc, stats)
+	}
+}
+
+func TestWithFallbackCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tc := reversedInts(5)
+
+	err := BogosortWith(ctx, slices.Clone(tc), cmp.Compare[int], WithFallback(time.Millisecond, Budget{Shuffles: 1}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithFallback) returns error: %v, want %v", ctx, tc, err, context.Canceled)
+	}
+}
@@ -0,0 +1,141 @@
+// Package metrics measures how far a slice is from being sorted.
+//
+// All functions take the cmp function with the same convention as
+// slices.SortFunc: cmp(a, b) should return a negative number when a < b,
+// a positive number when a > b and zero when a == b. Equal elements are never
+// out of order. None of the functions modifies the measured slice.
+package metrics
+
+import (
+	"slices"
+	"sort"
+)
+
+// Inversions returns the number of pairs (i, j) such that i < j and
+// x[i] > x[j]. It is also the least number of swaps of adjacent elements
+// which sort x. It uses merge sort on a copy of x, so the time complexity is
+// O(n*log(n)).
+func Inversions[S ~[]E, E any](x S, cmp func(a, b E) int) int {
+	y := slices.Clone(x)
+	buf := make([]E, len(x))
+
+	return mergeCount(y, buf, cmp)
+}
+
+// mergeCount sorts x with merge sort and returns the number of inversions.
+// Slice buf is a temporary storage of the same length as x.
+func mergeCount[E any](x, buf []E, cmp func(a, b E) int) int {
+	if len(x) < 2 {
+		return 0
+	}
+
+	mid := len(x) / 2
+	count := mergeCount(x[:mid], buf[:mid], cmp) + mergeCount(x[mid:], buf[mid:], cmp)
+
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(x) {
+		if cmp(x[j], x[i]) < 0 {
+			// x[j] is smaller than all remaining elements of the left half
+			count += mid - i
+			buf[k] = x[j]
+			j++
+		} else {
+			buf[k] = x[i]
+			i++
+		}
+		k++
+	}
+	k += copy(buf[k:], x[i:mid])
+	copy(buf[k:], x[j:])
+	copy(x, buf)
+
+	return count
+}
+
+// Runs returns the number of maximal non-decreasing runs of x. Sorted slice
+// has a single run, and a slice without elements has none.
+func Runs[S ~[]E, E any](x S, cmp func(a, b E) int) int {
+	if len(x) == 0 {
+		return 0
+	}
+
+	runs := 1
+	for i := 1; i < len(x); i++ {
+		if cmp(x[i], x[i-1]) < 0 {
+			runs++
+		}
+	}
+
+	return runs
+}
+
+// LongestNonDecreasing returns the length of the longest non-decreasing
+// subsequence of x. The difference between len(x) and the result is the least
+// number of elements which must be removed (or moved) to sort x. The time
+// complexity is O(n*log(n)).
+func LongestNonDecreasing[S ~[]E, E any](x S, cmp func(a, b E) int) int {
+	// tails[k] is the least last element of non-decreasing subsequences of
+	// length k+1 found so far
+	tails := make([]E, 0, len(x))
+	for _, v := range x {
+		k := sort.Search(len(tails), func(k int) bool { return cmp(tails[k], v) > 0 })
+		if k == len(tails) {
+			tails = append(tails, v)
+		} else {
+			tails[k] = v
+		}
+	}
+
+	return len(tails)
+}
+
+// SpearmanFootrule returns the sum of distances between positions of elements
+// in x and their positions in sorted x. Equal elements keep their original
+// order in sorted x, so they don't add to the distance.
+func SpearmanFootrule[S ~[]E, E any](x S, cmp func(a, b E) int) int {
+	sum := 0
+	for rank, i := range sortedIndices(x, cmp) {
+		sum += max(rank-i, i-rank)
+	}
+
+	return sum
+}
+
+// KendallTau returns the Kendall tau distance between x and sorted x,
+// normalized to the range [0, 1]: the number of inversions divided by
+// the number of pairs of unequal elements. Sorted slice has the distance 0
+// and a slice of distinct elements sorted in descending order has
+// the distance 1. Use Inversions for the distance which is not normalized.
+func KendallTau[S ~[]E, E any](x S, cmp func(a, b E) int) float64 {
+	y := slices.Clone(x)
+	buf := make([]E, len(x))
+	inversions := mergeCount(y, buf, cmp)
+
+	// y is sorted, so equal elements are adjacent
+	pairs := len(y) * (len(y) - 1) / 2
+	for i := 0; i < len(y); {
+		j := i + 1
+		for j < len(y) && cmp(y[i], y[j]) == 0 {
+			j++
+		}
+		pairs -= (j - i) * (j - i - 1) / 2
+		i = j
+	}
+	if pairs == 0 {
+		return 0
+	}
+
+	return float64(inversions) / float64(pairs)
+}
+
+// sortedIndices returns indices of elements of x in the order of stable
+// sorted x.
+func sortedIndices[S ~[]E, E any](x S, cmp func(a, b E) int) []int {
+	p := make([]int, len(x))
+	for i := range p {
+		p[i] = i
+	}
+	slices.SortStableFunc(p, func(a, b int) int { return cmp(x[a], x[b]) })
+
+	return p
+}
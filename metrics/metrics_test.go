@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestInversions(t *testing.T) {
+	testcases := []struct {
+		x    []int
+		want int
+	}{
+		{[]int{}, 0},
+		{[]int{1, 2, 3}, 0},
+		{[]int{1, 1, 1}, 0},
+		{[]int{2, 1, 3}, 1},
+		{[]int{3, 2, 1}, 3},
+		{[]int{5, 4, 3, 2, 1}, 10},
+		{[]int{1, 3, 2, 3, 1}, 4},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.x), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc.x)
+
+			got := Inversions(collection, cmp.Compare[int])
+			if got != tc.want {
+				t.Errorf("Inversions(%v, cmp.Compare) = %d, want %d", tc.x, got, tc.want)
+			}
+			if !slices.Equal(collection, tc.x) {
+				t.Errorf("Inversions(%v, cmp.Compare) modifies slice to %v", tc.x, collection)
+			}
+		})
+	}
+}
+
+func TestRuns(t *testing.T) {
+	testcases := []struct {
+		x    []int
+		want int
+	}{
+		{[]int{}, 0},
+		{[]int{1}, 1},
+		{[]int{1, 1, 2, 3}, 1},
+		{[]int{2, 1, 3}, 2},
+		{[]int{3, 2, 1}, 3},
+		{[]int{1, 3, 2, 3, 1}, 3},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.x), func(t *testing.T) {
+			t.Parallel()
+
+			if got := Runs(tc.x, cmp.Compare[int]); got != tc.want {
+				t.Errorf("Runs(%v, cmp.Compare) = %d, want %d", tc.x, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLongestNonDecreasing(t *testing.T) {
+	testcases := []struct {
+		x    []int
+		want int
+	}{
+		{[]int{}, 0},
+		{[]int{1, 2, 3}, 3},
+		{[]int{1, 1, 1}, 3},
+		{[]int{3, 2, 1}, 1},
+		{[]int{1, 3, 2, 3, 1}, 3},
+		{[]int{5, 1, 6, 2, 7, 3, 4}, 4},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.x), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc.x)
+
+			got := LongestNonDecreasing(collection, cmp.Compare[int])
+			if got != tc.want {
+				t.Errorf("LongestNonDecreasing(%v, cmp.Compare) = %d, want %d", tc.x, got, tc.want)
+			}
+			if !slices.Equal(collection, tc.x) {
+				t.Errorf("LongestNonDecreasing(%v, cmp.Compare) modifies slice to %v", tc.x, collection)
+			}
+		})
+	}
+}
+
+func TestSpearmanFootrule(t *testing.T) {
+	testcases := []struct {
+		x    []int
+		want int
+	}{
+		{[]int{}, 0},
+		{[]int{1, 2, 3}, 0},
+		{[]int{1, 1, 1}, 0},
+		{[]int{2, 1, 3}, 2},
+		{[]int{3, 2, 1}, 4},
+		{[]int{2, 3, 1}, 4},
+		{[]int{1, 3, 2, 3, 1}, 6},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.x), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc.x)
+
+			got := SpearmanFootrule(collection, cmp.Compare[int])
+			if got != tc.want {
+				t.Errorf("SpearmanFootrule(%v, cmp.Compare) = %d, want %d", tc.x, got, tc.want)
+			}
+			if !slices.Equal(collection, tc.x) {
+				t.Errorf("SpearmanFootrule(%v, cmp.Compare) modifies slice to %v", tc.x, collection)
+			}
+		})
+	}
+}
+
+func TestKendallTau(t *testing.T) {
+	testcases := []struct {
+		x    []int
+		want float64
+	}{
+		{[]int{}, 0},
+		{[]int{1, 1, 1}, 0},
+		{[]int{1, 2, 3, 4}, 0},
+		{[]int{4, 3, 2, 1}, 1},
+		{[]int{2, 1, 3, 4}, 1.0 / 6},
+		{[]int{2, 2, 1}, 1},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.x), func(t *testing.T) {
+			t.Parallel()
+
+			if got := KendallTau(tc.x, cmp.Compare[int]); got != tc.want {
+				t.Errorf("KendallTau(%v, cmp.Compare) = %v, want %v", tc.x, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMetricsCustomCmp(t *testing.T) {
+	descending := func(a, b string) int { return cmp.Compare(b, a) }
+	tc := []string{"c", "b", "a"}
+
+	if got := Inversions(tc, descending); got != 0 {
+		t.Errorf("Inversions(%v, descending) = %d, want 0", tc, got)
+	}
+	if got := Runs(tc, descending); got != 1 {
+		t.Errorf("Runs(%v, descending) = %d, want 1", tc, got)
+	}
+	if got := LongestNonDecreasing(tc, descending); got != len(tc) {
+		t.Errorf("LongestNonDecreasing(%v, descending) = %d, want %d", tc, got, len(tc))
+	}
+	if got := SpearmanFootrule(tc, descending); got != 0 {
+		t.Errorf("SpearmanFootrule(%v, descending) = %d, want 0", tc, got)
+	}
+	if got := KendallTau(tc, descending); got != 0 {
+		t.Errorf("KendallTau(%v, descending) = %v, want 0", tc, got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"slices"
+	"sync"
 	"time"
 )
 
@@ -23,31 +24,146 @@ func Miraclesort[S ~[]E, E cmp.Ordered](ctx context.Context, x S) error {
 // will be ever sorted. Function cmp(a, b) should return a negative number
 // when a < b, a positive number when a > b and zero when a == b.
 //
+// The sort is stable as long as miracles are: the function never moves
+// elements by itself.
+//
 // See https://en.wikipedia.org/wiki/Bogosort#Related_algorithms.
 func MiraclesortFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
-	// This implementation is based on assumption, that miracles occures from
-	// time to time.
-	//
-	// There are a couple of time units traditionally connected to miracles, but
-	// some are not specified enough to be useful (eg. "every now and then").
-	// The most appropriate units are:
-	//  - Jubeljahre: https://en.wiktionary.org/wiki/alle_Jubeljahre
-	//  - ruski rok: https://en.wiktionary.org/wiki/raz_na_ruski_rok
-	//  - blue moon: https://en.wiktionary.org/wiki/once_in_a_blue_moon
-	//  - zdrowaśka: https://en.wiktionary.org/wiki/zdrowa%C5%9Bka
-	//
-	// To provide smooth UI for user, interval should be short, and
-	// millizdrowaśka fits the requirements.
-	const millizdrowaska = 200 * time.Millisecond // 0.001 * 20 sec
-
-	for !slices.IsSortedFunc(x, cmp) {
-		select {
-		case <-ctx.Done():
-			return context.Cause(ctx)
-		default:
-			time.Sleep(millizdrowaska)
+	return MiraclesortWith(ctx, x, cmp)
+}
+
+// MiraclesortWith sorts the slice x of any type in ascending order as
+// determined by the cmp function with settings from opts. Applicable options
+// are WithPollInterval, WithClock, WithNotify, WithLocker, WithFallback,
+// WithStats, WithObserver, WithBudget and WithComparatorCheck. A context
+// controls cancellation, because miracles are non-deterministic and there is
+// no guarantees, that slice will be ever sorted.
+//
+// See https://en.wikipedia.org/wiki/Bogosort#Related_algorithms.
+func MiraclesortWith[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, opts ...Option) error {
+	return miraclesort(ctx, x, cmp, newOptions(opts), nil)
+}
+
+// MiraclesortNotifyFunc sorts the slice x of any type in ascending order as
+// determined by the cmp function. It checks the order of x periodically (see
+// WithPollInterval and WithClock options) and immediately after receiving
+// a value from the notify channel, so a goroutine which performs the miracle
+// can wake it up. A nil channel is never ready. A context controls
+// cancellation, because miracles are non-deterministic and there is no
+// guarantees, that slice will be ever sorted.
+//
+// See https://en.wikipedia.org/wiki/Bogosort#Related_algorithms.
+func MiraclesortNotifyFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, notify <-chan struct{}, opts ...Option) error {
+	o := newOptions(opts)
+	o.notify = notify
+
+	return miraclesort(ctx, x, cmp, o, nil)
+}
+
+// MiraclesortLocked sorts the slice x of any type in ascending order as
+// determined by the cmp function. It works like MiraclesortFunc, but holds
+// the lock mu during each check of the order, so other goroutines can modify
+// x without data races as long as they hold the same lock (see
+// PerformMiracle and CosmicRays). The order is checked periodically and after
+// each notification (see WithNotify option). A context controls cancellation,
+// because miracles are non-deterministic and there is no guarantees, that
+// slice will be ever sorted.
+//
+// See https://en.wikipedia.org/wiki/Bogosort#Related_algorithms.
+func MiraclesortLocked[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, mu sync.Locker, opts ...Option) error {
+	o := newOptions(opts)
+	o.locker = mu
+
+	return miraclesort(ctx, x, cmp, o, nil)
+}
+
+// PerformMiracle calls the miracle function while holding the lock mu. It is
+// a safe way to modify a slice watched by MiraclesortLocked with the same
+// lock:
+//
+//	var mu sync.Mutex
+//	go sortof.PerformMiracle(&mu, func() {
+//		slices.Sort(x)
+//	})
+//	err := sortof.MiraclesortLocked(ctx, x, cmp.Compare, &mu)
+func PerformMiracle(mu sync.Locker, miracle func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	miracle()
+}
+
+// miraclesortInterface waits until data is sorted. Miracles move elements of
+// data, so it is watched directly instead of through sorted indices, and
+// the fallback sorts data itself while holding the lock o.locker.
+func miraclesortInterface(ctx context.Context, data Interface, o options) error {
+	p := identity(data.Len()) // moved only by the fallback
+	return miraclesort(ctx, p, data.Compare, o, func() {
+		slices.SortStableFunc(p, data.Compare)
+		permute(data, p)
+	})
+}
+
+// miraclesort waits until x is sorted. The order is checked while holding
+// the lock o.locker, periodically and after each notification. When limits of
+// the fallback are exceeded, the fallback function is called while holding
+// the lock. A nil fallback sorts x with slices.SortStableFunc.
+func miraclesort[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options, fallback func()) error {
+	defer o.stats.since(time.Now())
+	ctx, cmp, o, cancel := prepare(ctx, cmp, o)
+	defer cancel()
+	if fallback == nil {
+		fallback = func() {
+			slices.SortStableFunc(x, cmp)
 		}
 	}
+	return withFallbackFunc(ctx, o, func(ctx context.Context, o options) error {
+		compare := observedCompare(x, cmp, o.observers)
+
+		sorted := func() bool {
+			o.locker.Lock()
+			defer o.locker.Unlock()
+
+			return isSorted(len(x), compare)
+		}
 
-	return nil
+		for !sorted() {
+			var tick <-chan time.Time
+			if o.pollInterval > 0 {
+				tick = o.clock.After(o.pollInterval)
+			}
+
+			o.observers.OnSleep()
+			select {
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			case <-o.notify:
+			case <-tick:
+			}
+		}
+
+		return inconsistency(ctx)
+	}, fallback)
 }
+
+// noLock is a sync.Locker which does nothing.
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+// millizdrowaska is the default interval between checks of the slice order.
+// Miraclesort is based on assumption, that miracles occures from time to
+// time.
+//
+// There are a couple of time units traditionally connected to miracles, but
+// some are not specified enough to be useful (eg. "every now and then").
+// The most appropriate units are:
+//   - Jubeljahre: https://en.wiktionary.org/wiki/alle_Jubeljahre
+//   - ruski rok: https://en.wiktionary.org/wiki/raz_na_ruski_rok
+//   - blue moon: https://en.wiktionary.org/wiki/once_in_a_blue_moon
+//   - zdrowaśka: https://en.wiktionary.org/wiki/zdrowa%C5%9Bka
+//
+// To provide smooth UI for user, interval should be short, and
+// millizdrowaśka fits the requirements.
+const millizdrowaska = 200 * time.Millisecond // 0.001 * 20 sec
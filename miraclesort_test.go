@@ -1,10 +1,12 @@
 package sortof
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"math"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -157,3 +159,121 @@ func TestMiraclesortStringUnsorted(t *testing.T) {
 		})
 	}
 }
+
+// fakeClock is a Clock which ticks only when the test says so. It reports
+// when the sorting function starts waiting, so the test can safely modify
+// the slice.
+type fakeClock struct {
+	waiting chan struct{}
+	ticks   chan time.Time
+}
+
+func newFakeClock() fakeClock {
+	return fakeClock{waiting: make(chan struct{}), ticks: make(chan time.Time)}
+}
+
+func (c fakeClock) After(time.Duration) <-chan time.Time {
+	c.waiting <- struct{}{}
+	return c.ticks
+}
+
+func TestMiraclesortNotifyFunc(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := []int{3, 1, 2}
+	clock := newFakeClock()
+	notify := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- MiraclesortNotifyFunc(ctx, collection, cmp.Compare[int], notify, WithClock(clock))
+	}()
+
+	<-clock.waiting
+	notify <- struct{}{} // no miracle yet
+	<-clock.waiting
+	collection[0], collection[1], collection[2] = 1, 2, 3
+	notify <- struct{}{} // miracle
+
+	if err := <-done; err != nil {
+		t.Errorf("MiraclesortNotifyFunc(%v, %v, cmp.Compare, notify) returns error: %v", ctx, collection, err)
+	}
+}
+
+func TestMiraclesortNotifyFuncClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := []string{"b", "a"}
+	clock := newFakeClock()
+	done := make(chan error)
+
+	go func() {
+		done <- MiraclesortNotifyFunc(ctx, collection, cmp.Compare[string], nil, WithClock(clock))
+	}()
+
+	<-clock.waiting
+	clock.ticks <- time.Now() // no miracle yet
+	<-clock.waiting
+	collection[0], collection[1] = collection[1], collection[0]
+	clock.ticks <- time.Now() // miracle
+
+	if err := <-done; err != nil {
+		t.Errorf("MiraclesortNotifyFunc(%v, %v, cmp.Compare, nil, fake clock) returns error: %v", ctx, collection, err)
+	}
+}
+
+func TestMiraclesortNotifyFuncCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tc := []int{3, 1, 2}
+
+	// cancellation does not wait for the next tick
+	err := MiraclesortNotifyFunc(ctx, tc, cmp.Compare[int], nil, WithPollInterval(time.Hour))
+	if err != context.Canceled {
+		t.Errorf("MiraclesortNotifyFunc(%v, %v, cmp.Compare, nil) returns error: %v, want %v", ctx, tc, err, context.Canceled)
+	}
+}
+
+func TestMiraclesortLocked(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var mu sync.Mutex
+	collection := []int{5, 2, 4, 1, 3}
+	tc := slices.Clone(collection)
+
+	// miracles happen one swap at a time
+	go func() {
+		for i := range tc {
+			for j := i + 1; j < len(tc); j++ {
+				time.Sleep(time.Millisecond)
+				PerformMiracle(&mu, func() {
+					if collection[j] < collection[i] {
+						collection[i], collection[j] = collection[j], collection[i]
+					}
+				})
+			}
+		}
+	}()
+
+	err := MiraclesortLocked(ctx, collection, cmp.Compare[int], &mu, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Errorf("MiraclesortLocked(%v, %v, cmp.Compare, &mu) returns error: %v", ctx, tc, err)
+	}
+	PerformMiracle(&mu, func() {
+		if !slices.IsSorted(collection) {
+			t.Errorf("MiraclesortLocked(%v, %v, cmp.Compare, &mu) cannot sort; got %v", ctx, tc, collection)
+		}
+	})
+}
+
+func TestMiraclesortLockedCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var mu sync.RWMutex
+	tc := []int{3, 1, 2}
+
+	err := MiraclesortLocked(ctx, tc, cmp.Compare[int], mu.RLocker())
+	if err != context.DeadlineExceeded {
+		t.Errorf("MiraclesortLocked(%v, %v, cmp.Compare, mu.RLocker()) returns error: %v, want %v", ctx, tc, err, context.DeadlineExceeded)
+	}
+}
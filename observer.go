@@ -0,0 +1,99 @@
+package sortof
+
+// Observer receives step-by-step events of sorting (see WithObserver), which
+// allows building visualizers, debuggers and progress bars. Indices refer to
+// positions in the sorted slice at the time of the event. Methods are called
+// synchronously by the sorting goroutine, so they should return quickly.
+// With concurrent workers (see WithWorkers) methods are called concurrently
+// and indices refer to the slice of the worker.
+type Observer interface {
+	// OnCompare is called after elements with indices i and j were compared.
+	OnCompare(i, j, result int)
+	// OnSwap is called after elements with indices i and j were exchanged.
+	OnSwap(i, j int)
+	// OnShuffle is called after the slice was shuffled for the iteration-th
+	// time (Bogosort).
+	OnShuffle(iteration int)
+	// OnPurge is called after the element with the index was deleted
+	// (Stalinsort).
+	OnPurge(index int)
+	// OnRecurse is called when sorting of elements with indices from i to j
+	// starts at the recursion depth (Slowsort).
+	OnRecurse(i, j, depth int)
+	// OnSleep is called when sorting starts waiting for a miracle
+	// (Miraclesort).
+	OnSleep()
+}
+
+// NopObserver is an Observer which ignores all events. It can be embedded in
+// observers which handle only some of the events.
+type NopObserver struct{}
+
+func (NopObserver) OnCompare(i, j, result int) {}
+func (NopObserver) OnSwap(i, j int)            {}
+func (NopObserver) OnShuffle(iteration int)    {}
+func (NopObserver) OnPurge(index int)          {}
+func (NopObserver) OnRecurse(i, j, depth int)  {}
+func (NopObserver) OnSleep()                   {}
+
+// observers is an Observer which passes events to all its elements in order.
+type observers []Observer
+
+func (obs observers) OnCompare(i, j, result int) {
+	for _, o := range obs {
+		o.OnCompare(i, j, result)
+	}
+}
+
+func (obs observers) OnSwap(i, j int) {
+	for _, o := range obs {
+		o.OnSwap(i, j)
+	}
+}
+
+func (obs observers) OnShuffle(iteration int) {
+	for _, o := range obs {
+		o.OnShuffle(iteration)
+	}
+}
+
+func (obs observers) OnPurge(index int) {
+	for _, o := range obs {
+		o.OnPurge(index)
+	}
+}
+
+func (obs observers) OnRecurse(i, j, depth int) {
+	for _, o := range obs {
+		o.OnRecurse(i, j, depth)
+	}
+}
+
+func (obs observers) OnSleep() {
+	for _, o := range obs {
+		o.OnSleep()
+	}
+}
+
+// observedCompare returns a function which compares elements of x with
+// the given indices using the cmp function and reports comparisons to obs.
+func observedCompare[S ~[]E, E any](x S, cmp func(a, b E) int, obs Observer) func(i, j int) int {
+	return func(i, j int) int {
+		result := cmp(x[i], x[j])
+		obs.OnCompare(i, j, result)
+
+		return result
+	}
+}
+
+// isSorted reports whether n elements are sorted in ascending order as
+// determined by the compare function of their indices.
+func isSorted(n int, compare func(i, j int) int) bool {
+	for i := 1; i < n; i++ {
+		if compare(i, i-1) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
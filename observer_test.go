@@ -0,0 +1,122 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recorder is an Observer which records events. It is safe for concurrent
+// use.
+type recorder struct {
+	NopObserver
+	mu         sync.Mutex
+	compares   int
+	swaps      [][2]int
+	iterations []int
+	purged     []int
+	recursions [][3]int
+}
+
+func (r *recorder) OnCompare(i, j, result int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compares++
+}
+
+func (r *recorder) OnSwap(i, j int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.swaps = append(r.swaps, [2]int{i, j})
+}
+
+func (r *recorder) OnShuffle(iteration int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.iterations = append(r.iterations, iteration)
+}
+
+func (r *recorder) OnPurge(index int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purged = append(r.purged, index)
+}
+
+func (r *recorder) OnRecurse(i, j, depth int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recursions = append(r.recursions, [3]int{i, j, depth})
+}
+
+// replay applies recorded swaps to x.
+func (r *recorder) replay(x []int) {
+	for _, s := range r.swaps {
+		x[s[0]], x[s[1]] = x[s[1]], x[s[0]]
+	}
+}
+
+func TestWithObserverBogosort(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 4, 1, 5}
+	for _, opts := range [][]Option{{WithSeed(7)}, {WithSeed(7), WithLockedPrefix()}} {
+		collection := slices.Clone(tc)
+		rec := &recorder{}
+
+		if err := BogosortWith(ctx, collection, cmp.Compare[int], append(opts, WithObserver(rec))...); err != nil {
+			t.Fatalf("BogosortWith(%v, %v, cmp.Compare, WithObserver) returns error: %v", ctx, tc, err)
+		}
+		replayed := slices.Clone(tc)
+		rec.replay(replayed)
+		if !slices.Equal(replayed, collection) {
+			t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithObserver) reports swaps which give %v, want %v", ctx, tc, replayed, collection)
+		}
+		for i, iteration := range rec.iterations {
+			if iteration != i+1 {
+				t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithObserver) reports shuffles %v, want consecutive iterations", ctx, tc, rec.iterations)
+				break
+			}
+		}
+		if rec.compares == 0 {
+			t.Errorf("BogosortWith(%v, %v, cmp.Compare, WithObserver) reports no comparisons", ctx, tc)
+		}
+	}
+}
+
+func TestWithObserverSlowsort(t *testing.T) {
+	ctx := context.Background()
+	tc := reversedInts(40)
+	for _, opts := range [][]Option{{}, {WithWorkers(2)}} {
+		collection := slices.Clone(tc)
+		rec := &recorder{}
+
+		if err := SlowsortWith(ctx, collection, cmp.Compare[int], append(opts, WithObserver(rec))...); err != nil {
+			t.Fatalf("SlowsortWith(%v, %v, cmp.Compare, WithObserver) returns error: %v", ctx, tc, err)
+		}
+		replayed := slices.Clone(tc)
+		rec.replay(replayed)
+		if !slices.IsSorted(replayed) {
+			t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithObserver) reports swaps which give %v", ctx, tc, replayed)
+		}
+		if len(rec.recursions) == 0 || rec.recursions[0] != [3]int{0, len(tc) - 1, 0} {
+			t.Errorf("SlowsortWith(%v, %v, cmp.Compare, WithObserver) reports recursion %v, want start from whole slice", ctx, tc, rec.recursions)
+		}
+	}
+}
+
+func TestWithObserverStalinsort(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 4, 1, 5, 9, 2, 6}
+	rec := &recorder{}
+
+	if _, err := StalinsortWith(ctx, tc, cmp.Compare[int], WithObserver(rec)); err != nil {
+		t.Fatalf("StalinsortWith(%v, %v, cmp.Compare, WithObserver) returns error: %v", ctx, tc, err)
+	}
+	if want := []int{1, 3, 6, 7}; !slices.Equal(rec.purged, want) {
+		t.Errorf("StalinsortWith(%v, %v, cmp.Compare, WithObserver) reports purged %v, want %v", ctx, tc, rec.purged, want)
+	}
+	if rec.compares != len(tc)-1 {
+		t.Errorf("StalinsortWith(%v, %v, cmp.Compare, WithObserver) reports %d comparisons, want %d", ctx, tc, rec.compares, len(tc)-1)
+	}
+}
@@ -0,0 +1,247 @@
+package sortof
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Option configures optional behaviour of sorting functions. Functions ignore
+// options which are not applicable to them.
+type Option func(*options)
+
+// options contains optional settings of sorting functions.
+type options struct {
+	newShuffler     func(worker int) Shuffler
+	seed            *int64
+	lockPrefix      bool
+	bestEffort      bool
+	stable          bool
+	workers         int
+	slowsortState   *SlowsortState
+	checkComparator bool
+	stats           *Stats
+	observers       observers
+	budget          Budget
+	fallback        bool
+	fallbackAfter   time.Duration
+	fallbackBudget  Budget
+	locker          sync.Locker
+	pollInterval    time.Duration
+	clock           Clock
+	notify          <-chan struct{}
+}
+
+// newOptions returns default settings modified by opts.
+func newOptions(opts []Option) options {
+	o := options{
+		workers:      1,
+		locker:       noLock{},
+		pollInterval: millizdrowaska,
+		clock:        realClock{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// shuffler returns a Shuffler for the worker numbered from 0. By default
+// a single worker uses top-level functions from math/rand package and
+// concurrent workers get their own random sources.
+func (o options) shuffler(worker int) Shuffler {
+	switch {
+	case o.newShuffler != nil:
+		return o.newShuffler(worker)
+	case o.workers > 1:
+		return NewSeededShuffler(rand.Int63())
+	}
+
+	return globalShuffler{}
+}
+
+// random returns a source of random numbers seeded by WithSeed. By default
+// the seed is random.
+func (o options) random() *rand.Rand {
+	if o.seed != nil {
+		return rand.New(rand.NewSource(*o.seed))
+	}
+
+	return rand.New(rand.NewSource(rand.Int63()))
+}
+
+// WithShuffler sets the source of permutations used by Bogosort. With
+// concurrent workers (see WithWorkers) r is shared by all of them, so it must
+// be safe for concurrent use.
+func WithShuffler(r Shuffler) Option {
+	return func(o *options) {
+		o.newShuffler = func(int) Shuffler { return r }
+	}
+}
+
+// WithSeed sets the seed of random numbers used by Bogosort and CosmicRays, so
+// a run can be reproduced. Concurrent workers (see WithWorkers) use
+// consecutive seeds.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = &seed
+		o.newShuffler = func(worker int) Shuffler {
+			return NewSeededShuffler(seed + int64(worker))
+		}
+	}
+}
+
+// WithLockedPrefix makes Bogosort shuffle only elements after the longest
+// prefix which is already in final position (see BogosortLockedPrefixFunc).
+func WithLockedPrefix() Option {
+	return func(o *options) {
+		o.lockPrefix = true
+	}
+}
+
+// WithBestEffort makes Bogosort remember the most sorted permutation seen so
+// far (the one with the least number of inversions) and restore it when
+// sorting is interrupted. Then the returned error is a *PartialSortError.
+func WithBestEffort() Option {
+	return func(o *options) {
+		o.bestEffort = true
+	}
+}
+
+// WithStable makes sorting stable: equal elements keep their original order.
+// Algorithms which are stable by nature ignore it.
+func WithStable() Option {
+	return func(o *options) {
+		o.stable = true
+	}
+}
+
+// WithWorkers sets the number of goroutines used by Bogosort and Slowsort
+// (see BogosortParallelFunc and SlowsortParallelFunc). When n <= 0,
+// runtime.GOMAXPROCS(0) goroutines are used. The default is 1.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		o.workers = n
+	}
+}
+
+// WithSlowsortState makes Slowsort start from the given state and update it
+// during sorting, so interrupted sorting can be resumed (see
+// SlowsortWithState). Sorting with a state is sequential. With WithStable
+// the state keeps original positions of elements, so resumed sorting stays
+// stable.
+func WithSlowsortState(state *SlowsortState) Option {
+	return func(o *options) {
+		o.slowsortState = state
+	}
+}
+
+// WithComparatorCheck makes sorting stop with a *ComparatorError when
+// the comparison function turns out to be inconsistent (see CheckComparator).
+func WithComparatorCheck() Option {
+	return func(o *options) {
+		o.checkComparator = true
+	}
+}
+
+// WithStats makes sorting functions record the amount of done work in s.
+func WithStats(s *Stats) Option {
+	return func(o *options) {
+		o.stats = s
+		o.observers = append(o.observers, s)
+	}
+}
+
+// WithObserver makes sorting functions report step-by-step events to obs.
+// With multiple observers, events are reported in the order of options.
+func WithObserver(obs Observer) Option {
+	return func(o *options) {
+		o.observers = append(o.observers, obs)
+	}
+}
+
+// WithBudget limits the work of sorting functions. When any limit is
+// exceeded, sorting stops with a *BudgetError.
+func WithBudget(b Budget) Option {
+	return func(o *options) {
+		o.budget = b
+	}
+}
+
+// WithLocker sets the lock held by Miraclesort during each check of the order
+// (see MiraclesortLocked).
+func WithLocker(mu sync.Locker) Option {
+	return func(o *options) {
+		o.locker = mu
+	}
+}
+
+// WithPollInterval sets the interval between checks of slice order in
+// Miraclesort. Non-positive interval disables periodic checks. The default
+// interval is 200ms.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = d
+	}
+}
+
+// WithNotify sets the channel which wakes up Miraclesort, so it checks
+// the order of slice immediately after receiving a value from it.
+func WithNotify(notify <-chan struct{}) Option {
+	return func(o *options) {
+		o.notify = notify
+	}
+}
+
+// WithClock sets the source of time used for waiting. It allows tests to
+// inject a fake clock instead of waiting in real time.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// prepare applies settings from o, which wrap the context and the cmp
+// function: consistency checks of cmp (see CheckComparator) and the budget.
+// It returns the wrapped context, cmp and options. Calling cancel releases
+// resources associated with the returned context.
+func prepare[E any](ctx context.Context, cmp func(a, b E) int, o options) (context.Context, func(a, b E) int, options, context.CancelFunc) {
+	cancel := func() {}
+	if o.checkComparator {
+		ctx, cmp, cancel = CheckComparator(ctx, cmp)
+	}
+	if o.budget != (Budget{}) {
+		var cancelBudget context.CancelCauseFunc
+		ctx, cancelBudget = context.WithCancelCause(ctx)
+		o.observers = append(slices.Clip(o.observers), &budgetObserver{limit: o.budget, fail: cancelBudget})
+		cancelCheck := cancel
+		cancel = func() {
+			cancelBudget(context.Canceled)
+			cancelCheck()
+		}
+	}
+
+	return ctx, cmp, o, cancel
+}
+
+// Clock is a source of time.
+type Clock interface {
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is a Clock which uses functions from the time package.
+type realClock struct{}
+
+// After works like time.After.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,50 @@
+package sortof
+
+import (
+	"fmt"
+
+	"github.com/macie/sortof/metrics"
+)
+
+// PartialSortError is returned when sorting was interrupted before the slice
+// was fully sorted. It describes how far along the sort was, so a caller can
+// decide whether the partial result is useful. It wraps the cause of
+// the interruption (usually context.Cause of the sorting context).
+type PartialSortError struct {
+	Err          error
+	Len          int // length of the slice, not the sorting progress
+	SortedPrefix int // length of the longest non-decreasing prefix
+	Inversions   int // number of pairs of elements in the wrong order
+}
+
+// newPartialSortError returns a *PartialSortError which describes the order
+// of x as determined by the cmp function.
+func newPartialSortError[S ~[]E, E any](err error, x S, cmp func(a, b E) int) *PartialSortError {
+	return &PartialSortError{
+		Err:          err,
+		Len:          len(x),
+		SortedPrefix: sortedPrefix(x, cmp),
+		Inversions:   metrics.Inversions(x, cmp),
+	}
+}
+
+// Error returns the cause of the interruption with sorting progress.
+func (e *PartialSortError) Error() string {
+	return fmt.Sprintf("%v (sorted prefix: %d of %d, inversions: %d)", e.Err, e.SortedPrefix, e.Len, e.Inversions)
+}
+
+// Unwrap returns the cause of the interruption.
+func (e *PartialSortError) Unwrap() error {
+	return e.Err
+}
+
+// sortedPrefix returns length of the longest non-decreasing prefix of x.
+func sortedPrefix[S ~[]E, E any](x S, cmp func(a, b E) int) int {
+	for i := 1; i < len(x); i++ {
+		if cmp(x[i], x[i-1]) < 0 {
+			return i
+		}
+	}
+
+	return len(x)
+}
@@ -0,0 +1,60 @@
+package sortof
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+)
+
+// Shuffler pseudo-randomizes the order of elements. Shuffle should behave
+// like rand.Shuffle: it calls swap for pairs of indices in the range [0, n),
+// so every permutation of n elements is equally likely.
+//
+// Both *rand.Rand and the results of NewSeededShuffler and NewCryptoShuffler
+// implement the interface.
+type Shuffler interface {
+	Shuffle(n int, swap func(i, j int))
+}
+
+// NewSeededShuffler returns a Shuffler with a deterministic sequence of
+// permutations, so a sorting run can be replayed with the same seed.
+// Returned Shuffler is not safe for concurrent use by multiple goroutines.
+func NewSeededShuffler(seed int64) Shuffler {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewCryptoShuffler returns a Shuffler backed by cryptographically secure
+// random number generator from crypto/rand package. It panics when
+// the operating system cannot provide random bytes.
+func NewCryptoShuffler() Shuffler {
+	return rand.New(cryptoSource{})
+}
+
+// globalShuffler uses top-level functions from math/rand package. It is safe
+// for concurrent use by multiple goroutines.
+type globalShuffler struct{}
+
+// Shuffle pseudo-randomizes the order of elements with rand.Shuffle.
+func (globalShuffler) Shuffle(n int, swap func(i, j int)) {
+	rand.Shuffle(n, swap)
+}
+
+// cryptoSource is a rand.Source64 which reads numbers from crypto/rand.
+type cryptoSource struct{}
+
+// Int63 returns a non-negative random 63-bit integer.
+func (s cryptoSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Uint64 returns a random 64-bit integer.
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Seed is a no-op, because crypto/rand cannot be seeded.
+func (cryptoSource) Seed(int64) {}
@@ -0,0 +1,28 @@
+package sortof_test
+
+import (
+	"testing"
+
+	"github.com/macie/sortof"
+	"github.com/macie/sortof/sortoftest"
+)
+
+func TestShufflerUniform(t *testing.T) {
+	testcases := map[string]sortof.Shuffler{
+		"GlobalShuffler":    sortof.GlobalShuffler,
+		"NewSeededShuffler": sortof.NewSeededShuffler(1),
+		"NewCryptoShuffler": sortof.NewCryptoShuffler(),
+	}
+	for name, tc := range testcases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for n := 2; n <= 4; n++ {
+				if err := sortoftest.CheckUniformShuffle(tc, n, 24000); err != nil {
+					t.Errorf("CheckUniformShuffle(%s, %d, 24000) returns error: %v", name, n, err)
+				}
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package sortof
 import (
 	"cmp"
 	"context"
-	"math"
+	"fmt"
+	"sync"
+	"time"
 )
 
 // Slowsort sorts the slice x of any ordered type in ascending order. It is
 // practical implementation of multiply and surrender paradigm.
 //
 // When sorting floating-point numbers, NaNs are ordered before other values.
-// Cancelled context can leave slice partially ordered.
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError.
 //
 // According to algorithm authors, slowsort is most suitable for hourly rated
 // programmers.
@@ -18,41 +21,312 @@ import (
 // See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
 // Analysis. https://doi.org/10.1145/990534.990536
 func Slowsort[S ~[]E, E cmp.Ordered](ctx context.Context, x S) error {
-	return slowsort(ctx, x, 0, len(x)-1, cmp.Compare)
+	return SlowsortFunc(ctx, x, cmp.Compare)
 }
 
 // SlowsortFunc sorts the slice x of any type in ascending order as
 // determined by the cmp function. Function cmp(a, b) should return a negative
 // number when a < b, a positive number when a > b and zero when a == b.
 //
-// Cancelled context can leave slice partially ordered.
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError which wraps context.Cause(ctx) and describes
+// the progress of sorting. The sort is not guaranteed to be stable. Use
+// SlowsortStableFunc to keep the original order of equal elements.
 //
 // See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
 // Analysis. https://doi.org/10.1145/990534.990536
 func SlowsortFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
-	return slowsort(ctx, x, 0, len(x)-1, cmp)
+	return SlowsortWith(ctx, x, cmp)
 }
 
-// slowsort sorts x[i:j].
-// The algorithm is based on multiple and surrender design with cancellation.
+// SlowsortWith sorts the slice x of any type in ascending order as
+// determined by the cmp function with settings from opts. Applicable options
+// are WithStable, WithWorkers, WithSlowsortState, WithFallback, WithStats,
+// WithObserver, WithBudget and WithComparatorCheck.
+//
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError.
+//
+// See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
+// Analysis. https://doi.org/10.1145/990534.990536
+func SlowsortWith[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, opts ...Option) error {
+	o := newOptions(opts)
+	defer o.stats.since(time.Now())
+	ctx, cmp, o, cancel := prepare(ctx, cmp, o)
+	defer cancel()
+
+	if o.stable {
+		return stableFunc(x, cmp, func(y []indexed[E], cmp func(a, b indexed[E]) int) error {
+			if o.slowsortState != nil {
+				restorePositions(y, o.slowsortState)
+				defer savePositions(y, o.slowsortState)
+			}
+			return slowsortUnstable(ctx, y, cmp, o)
+		})
+	}
+
+	return slowsortUnstable(ctx, x, cmp, o)
+}
+
+// restorePositions sets original positions of elements of y saved in
+// the state by interrupted stable sorting. Otherwise elements of y keep their
+// current positions.
+func restorePositions[E any](y []indexed[E], state *SlowsortState) {
+	if !state.Started || len(state.Positions) != len(y) {
+		return
+	}
+	for i := range y {
+		y[i].index = state.Positions[i]
+	}
+}
+
+// savePositions saves original positions of elements of y in the state, so
+// stable sorting can be resumed.
+func savePositions[E any](y []indexed[E], state *SlowsortState) {
+	state.Positions = make([]int, len(y))
+	for i := range y {
+		state.Positions[i] = y[i].index
+	}
+}
+
+// slowsortUnstable sorts x with settings from o, except stability. Sorting
+// with a saved state is always sequential.
+func slowsortUnstable[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, o options) error {
+	return withFallback(ctx, x, cmp, o, func(ctx context.Context, o options) error {
+		var err error
+		compare := observedCompare(x, cmp, o.observers)
+		switch {
+		case o.slowsortState != nil:
+			if err := o.slowsortState.init(len(x)); err != nil {
+				return err
+			}
+			err = slowsort(ctx, x, compare, o.slowsortState, 0, o.observers)
+		case o.workers > 1:
+			// the calling goroutine is one of the workers
+			sem := make(chan struct{}, o.workers-1)
+			err = slowsortParallel(ctx, x, 0, len(x)-1, 0, compare, sem, o.observers)
+		default:
+			err = slowsort(ctx, x, compare, newSlowsortState(len(x)), 0, o.observers)
+		}
+		if err == nil {
+			err = inconsistency(ctx)
+		}
+		if err != nil {
+			return newPartialSortError(err, x, cmp)
+		}
+
+		return nil
+	})
+}
+
+// SlowsortStableFunc sorts the slice x of any type in ascending order as
+// determined by the cmp function while keeping the original order of equal
+// elements. Ties are broken by the original positions of elements.
+//
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError.
+//
+// See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
+// Analysis. https://doi.org/10.1145/990534.990536
+func SlowsortStableFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) error {
+	return SlowsortWith(ctx, x, cmp, WithStable())
+}
+
+// SlowsortParallel sorts the slice x of any ordered type in ascending order.
+// It works like Slowsort, but independent halves of the slice are sorted
+// concurrently by at most workers goroutines. When workers <= 0,
+// runtime.GOMAXPROCS(0) goroutines are used.
+//
+// When sorting floating-point numbers, NaNs are ordered before other values.
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError.
+func SlowsortParallel[S ~[]E, E cmp.Ordered](ctx context.Context, x S, workers int) error {
+	return SlowsortParallelFunc(ctx, x, cmp.Compare, workers)
+}
+
+// SlowsortParallelFunc sorts the slice x of any type in ascending order as
+// determined by the cmp function. It works like SlowsortFunc, but independent
+// halves of the slice are sorted concurrently by at most workers goroutines.
+// When workers <= 0, runtime.GOMAXPROCS(0) goroutines are used. Short parts
+// of the slice are always sorted sequentially, because the cost of starting
+// goroutine would outweigh the gain. Function cmp must be safe for concurrent
+// use.
+//
+// Cancelled context can leave slice partially ordered. Then the returned
+// error is a *PartialSortError.
+//
+// See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
+// Analysis. https://doi.org/10.1145/990534.990536
+func SlowsortParallelFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, workers int) error {
+	return SlowsortWith(ctx, x, cmp, WithWorkers(workers))
+}
+
+// SlowsortState is a snapshot of Slowsort progress. It can be saved (e.g.
+// with encoding/json) together with the partially sorted slice when
+// the context is cancelled, and passed back later to SlowsortWithState to
+// continue sorting exactly where it stopped. The zero value is a state of
+// sorting which has not started yet.
+type SlowsortState struct {
+	Len       int             // length of the sorted slice
+	Started   bool            // whether sorting has started
+	Frames    []SlowsortFrame // explicit stack of sorting steps
+	Positions []int           // original positions of elements (stable sorting only)
+}
+
+// SlowsortFrame is a single step of Slowsort, which sorts x[I:J+1].
+type SlowsortFrame struct {
+	I, J  int
+	Phase int // number of finished sub-steps of the frame
+}
+
+// Phases of SlowsortFrame. Sorting of x[i:j+1] consists of sorting both
+// halves, moving the maximum to the end and sorting everything except
+// the maximum.
+const (
+	slowsortLeftHalf = iota
+	slowsortRightHalf
+	slowsortMaximum
+)
+
+// SlowsortWithState sorts the slice x of any type in ascending order as
+// determined by the cmp function, starting from the given state. The state is
+// updated during sorting, so when a cancelled context interrupts sorting,
+// the state describes the progress and can be used to resume it later with
+// the same slice. Then the returned error is a *PartialSortError.
+//
+// See: Andrei Broder and Jorge Stolfi. Pessimal Algorithms and Simplexity
+// Analysis. https://doi.org/10.1145/990534.990536
+func SlowsortWithState[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, state *SlowsortState) error {
+	return SlowsortWith(ctx, x, cmp, WithSlowsortState(state))
+}
+
+// slowsort sorts x using explicit stack of frames from the state instead of
+// recursion. The algorithm is based on multiple and surrender design with
+// cancellation. Elements are compared by the compare function of their
+// indices, and steps are reported to obs with recursion depth increased by
+// depth.
 // slowsort paper: https://doi.org/10.1145/990534.990536
-func slowsort[S ~[]E, E any](ctx context.Context, x S, i int, j int, cmp func(a, b E) int) error {
-	select {
-	case <-ctx.Done():
-		return context.Cause(ctx)
-	default:
-		if i >= j {
-			return nil
-		}
-
-		mid := int(math.Floor(float64((i + j) / 2)))
-		slowsort(ctx, x, i, mid, cmp)
-		slowsort(ctx, x, mid+1, j, cmp)
-		if cmp(x[j], x[mid]) == -1 {
+func slowsort[S ~[]E, E any](ctx context.Context, x S, compare func(i, j int) int, state *SlowsortState, depth int, obs Observer) error {
+	for len(state.Frames) > 0 {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		default:
+		}
+
+		top := len(state.Frames) - 1
+		frame := &state.Frames[top]
+		if frame.Phase == slowsortLeftHalf {
+			obs.OnRecurse(frame.I, frame.J, depth+top)
+		}
+		if frame.I >= frame.J {
+			state.Frames = state.Frames[:top]
+			continue
+		}
+
+		mid := (frame.I + frame.J) / 2
+		switch frame.Phase {
+		case slowsortLeftHalf:
+			frame.Phase = slowsortRightHalf
+			state.Frames = append(state.Frames, SlowsortFrame{I: frame.I, J: mid})
+		case slowsortRightHalf:
+			frame.Phase = slowsortMaximum
+			state.Frames = append(state.Frames, SlowsortFrame{I: mid + 1, J: frame.J})
+		case slowsortMaximum:
+			if compare(frame.J, mid) < 0 {
+				x[mid], x[frame.J] = x[frame.J], x[mid]
+				obs.OnSwap(mid, frame.J)
+			}
+			// the maximum is in place, so the frame is replaced with sorting
+			// of the remaining elements
+			*frame = SlowsortFrame{I: frame.I, J: frame.J - 1}
+		}
+	}
+
+	return nil
+}
+
+// slowsortParallelCutoff is the length of the slice below which Slowsort
+// stays sequential.
+const slowsortParallelCutoff = 32
+
+// slowsortParallel sorts x[i:j+1] and uses new goroutines for sorting left
+// halves as long as the semaphore sem has free slots. Elements are compared by
+// the compare function of their indices, and steps at the recursion depth are
+// reported to obs.
+func slowsortParallel[S ~[]E, E any](ctx context.Context, x S, i, j, depth int, compare func(i, j int) int, sem chan struct{}, obs Observer) error {
+	for ; j-i+1 >= slowsortParallelCutoff; j-- {
+		obs.OnRecurse(i, j, depth)
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		default:
+		}
+
+		mid := (i + j) / 2
+		var leftErr, rightErr error
+		select {
+		case sem <- struct{}{}:
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				leftErr = slowsortParallel(ctx, x, i, mid, depth+1, compare, sem, obs)
+				<-sem
+			}()
+			rightErr = slowsortParallel(ctx, x, mid+1, j, depth+1, compare, sem, obs)
+			wg.Wait()
+		default:
+			leftErr = slowsortParallel(ctx, x, i, mid, depth+1, compare, sem, obs)
+			if leftErr == nil {
+				rightErr = slowsortParallel(ctx, x, mid+1, j, depth+1, compare, sem, obs)
+			}
+		}
+		if leftErr != nil {
+			return leftErr
+		}
+		if rightErr != nil {
+			return rightErr
+		}
+
+		if compare(j, mid) < 0 {
 			x[mid], x[j] = x[j], x[mid]
+			obs.OnSwap(mid, j)
 		}
+	}
+
+	return slowsort(ctx, x, compare, &SlowsortState{Frames: []SlowsortFrame{{I: i, J: j}}}, depth, obs)
+}
 
-		slowsort(ctx, x, i, j-1, cmp)
+// newSlowsortState returns a state of sorting a slice of n elements, which
+// has just started.
+func newSlowsortState(n int) *SlowsortState {
+	return &SlowsortState{Len: n, Started: true, Frames: []SlowsortFrame{{I: 0, J: n - 1}}}
+}
+
+// init starts sorting of a slice of n elements if it has not started yet,
+// and returns an error if the state cannot be used for the slice.
+func (s *SlowsortState) init(n int) error {
+	if !s.Started {
+		*s = *newSlowsortState(n)
+	}
+
+	return s.validate(n)
+}
+
+// validate returns an error if the state cannot be used for sorting a slice
+// of n elements.
+func (s *SlowsortState) validate(n int) error {
+	if s.Len != n {
+		return fmt.Errorf("slowsort state of %d elements cannot be used for slice of %d elements", s.Len, n)
+	}
+	if len(s.Positions) != 0 && len(s.Positions) != n {
+		return fmt.Errorf("slowsort state contains %d positions of elements, want %d", len(s.Positions), n)
+	}
+	for _, f := range s.Frames {
+		if f.I < 0 || f.J >= n || f.Phase < slowsortLeftHalf || f.Phase > slowsortMaximum {
+			return fmt.Errorf("slowsort state contains invalid frame %+v", f)
+		}
 	}
 
 	return nil
@@ -1,11 +1,17 @@
 package sortof
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
+	"runtime"
 	"slices"
 	"testing"
+	"time"
 )
 
 func TestSlowsortFloat(t *testing.T) {
@@ -87,3 +93,233 @@ func TestSlowsortString(t *testing.T) {
 		})
 	}
 }
+
+func TestSlowsortStableFunc(t *testing.T) {
+	ctx := context.Background()
+	type record struct {
+		key  int
+		name string
+	}
+	cmpKeys := func(a, b record) int { return cmp.Compare(a.key, b.key) }
+	testcases := [][]record{
+		{{1, "a"}, {1, "b"}, {1, "c"}},
+		{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}, {0, "e"}},
+		{{3, "a"}, {3, "b"}, {2, "c"}, {3, "d"}, {1, "e"}, {2, "f"}, {1, "g"}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprint(tc), func(t *testing.T) {
+			t.Parallel()
+			collection := slices.Clone(tc)
+			want := slices.Clone(tc)
+			slices.SortStableFunc(want, cmpKeys)
+
+			err := SlowsortStableFunc(ctx, collection, cmpKeys)
+			if err != nil {
+				t.Errorf("SlowsortStableFunc(%v, %v, cmpKeys) returns error: %v", ctx, tc, err)
+			}
+			if !slices.Equal(collection, want) {
+				t.Errorf("SlowsortStableFunc(%v, %v, cmpKeys) is not stable; got %v, want %v", ctx, tc, collection, want)
+			}
+		})
+	}
+}
+
+func TestSlowsortCancelled(t *testing.T) {
+	testcases := []time.Duration{0, time.Millisecond}
+	for _, timeout := range testcases {
+		timeout := timeout
+		t.Run(fmt.Sprint(timeout), func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			tc := make([]int, 100)
+			for i := range tc {
+				tc[i] = len(tc) - i
+			}
+			collection := slices.Clone(tc)
+
+			err := Slowsort(ctx, collection)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Slowsort(%v, %v) returns error: %v, want %v", ctx, tc, err, context.DeadlineExceeded)
+			}
+			var partialErr *PartialSortError
+			if !errors.As(err, &partialErr) {
+				t.Fatalf("Slowsort(%v, %v) returns error: %T, want *PartialSortError", ctx, tc, err)
+			}
+			if partialErr.Len != len(tc) || partialErr.Inversions == 0 || partialErr.SortedPrefix == len(tc) {
+				t.Errorf("Slowsort(%v, %v) returns %+v for partially sorted slice %v", ctx, tc, partialErr, collection)
+			}
+		})
+	}
+}
+
+func TestSlowsortWithStateResume(t *testing.T) {
+	tc := []int{9, 3, 7, 1, 8, 2, 6, 4, 5, 0, 11, 10}
+	collection := slices.Clone(tc)
+	state := &SlowsortState{}
+
+	// cancelled context interrupts sorting after every few steps
+	interruptions := 0
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		steps := 0
+		countSteps := func(a, b int) int {
+			steps++
+			if steps == 5 {
+				cancel()
+			}
+			return cmp.Compare(a, b)
+		}
+
+		err := SlowsortWithState(ctx, collection, countSteps, state)
+		cancel()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("SlowsortWithState(ctx, %v, cmp.Compare, state) returns error: %v", tc, err)
+		}
+
+		// state survives serialization
+		saved, err := json.Marshal(state)
+		if err != nil {
+			t.Fatalf("json.Marshal(%+v) returns error: %v", state, err)
+		}
+		state = &SlowsortState{}
+		if err := json.Unmarshal(saved, state); err != nil {
+			t.Fatalf("json.Unmarshal(%s) returns error: %v", saved, err)
+		}
+		interruptions++
+	}
+
+	if interruptions == 0 {
+		t.Errorf("SlowsortWithState(ctx, %v, cmp.Compare, state) was never interrupted", tc)
+	}
+	if !slices.IsSorted(collection) {
+		want := slices.Clone(tc)
+		slices.Sort(want)
+		t.Errorf("SlowsortWithState(ctx, %v, cmp.Compare, state) cannot sort after %d interruptions; got %v, want %v", tc, interruptions, collection, want)
+	}
+}
+
+func TestSlowsortWithStateStable(t *testing.T) {
+	type element struct{ key, pos int }
+	byKey := func(a, b element) int { return cmp.Compare(a.key, b.key) }
+	tc := make([]element, 12)
+	for i := range tc {
+		tc[i] = element{key: (len(tc) - i) % 3, pos: i}
+	}
+	want := slices.Clone(tc)
+	slices.SortStableFunc(want, byKey)
+	collection := slices.Clone(tc)
+	state := &SlowsortState{}
+
+	// cancelled context interrupts sorting after every few steps
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		steps := 0
+		countSteps := func(a, b element) int {
+			steps++
+			if steps == 5 {
+				cancel()
+			}
+			return byKey(a, b)
+		}
+
+		err := SlowsortWith(ctx, collection, countSteps, WithStable(), WithSlowsortState(state))
+		cancel()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("SlowsortWith(ctx, %v, byKey, WithStable(), WithSlowsortState(state)) returns error: %v", tc, err)
+		}
+	}
+
+	if !slices.Equal(collection, want) {
+		t.Errorf("SlowsortWith(ctx, %v, byKey, WithStable(), WithSlowsortState(state)) is not stable after interruptions; got %v, want %v", tc, collection, want)
+	}
+}
+
+func TestSlowsortWithStateInvalid(t *testing.T) {
+	ctx := context.Background()
+	testcases := []SlowsortState{
+		{Len: 2, Started: true},
+		{Len: 3, Started: true, Frames: []SlowsortFrame{{I: 0, J: 3}}},
+		{Len: 3, Started: true, Frames: []SlowsortFrame{{I: 0, J: 2, Phase: 7}}},
+		{Len: 3, Started: true, Positions: []int{0, 1}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(fmt.Sprintf("%+v", tc), func(t *testing.T) {
+			t.Parallel()
+			collection := []int{3, 2, 1}
+
+			err := SlowsortWithState(ctx, collection, cmp.Compare[int], &tc)
+			if err == nil {
+				t.Errorf("SlowsortWithState(%v, %v, cmp.Compare, %+v) returns no error", ctx, collection, tc)
+			}
+		})
+	}
+}
+
+func TestSlowsortParallelFunc(t *testing.T) {
+	ctx := context.Background()
+	cmpInts := func(a, b int) int { return cmp.Compare(a, b) }
+	testcases := map[string][]int{
+		"empty":    {},
+		"short":    {3, 1, 2},
+		"reversed": reversedInts(2 * slowsortParallelCutoff),
+		"random":   rand.Perm(3 * slowsortParallelCutoff),
+	}
+	for name, tc := range testcases {
+		for _, workers := range []int{0, 1, 4} {
+			name, tc, workers := name, tc, workers
+			t.Run(fmt.Sprintf("%s/workers=%d", name, workers), func(t *testing.T) {
+				t.Parallel()
+				collection := slices.Clone(tc)
+
+				err := SlowsortParallelFunc(ctx, collection, cmpInts, workers)
+				if err != nil {
+					t.Errorf("SlowsortParallelFunc(%v, %v, cmpInts, %d) returns error: %v", ctx, tc, workers, err)
+				}
+				if !slices.IsSorted(collection) {
+					want := slices.Clone(tc)
+					slices.Sort(want)
+					t.Errorf("SlowsortParallelFunc(%v, %v, cmpInts, %d) cannot sort; got %v, want %v", ctx, tc, workers, collection, want)
+				}
+			})
+		}
+	}
+}
+
+func TestSlowsortParallelCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	tc := reversedInts(200)
+
+	err := SlowsortParallel(ctx, slices.Clone(tc), 4)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SlowsortParallel(%v, %v, 4) returns error: %v, want %v", ctx, tc, err, context.DeadlineExceeded)
+	}
+}
+
+func BenchmarkSlowsort(b *testing.B) {
+	ctx := context.Background()
+	for _, n := range []int{48, 64} {
+		input := reversedInts(n)
+		b.Run(fmt.Sprintf("n=%d/sequential", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Slowsort(ctx, slices.Clone(input))
+			}
+		})
+		for _, workers := range []int{2, 4, runtime.GOMAXPROCS(0)} {
+			b.Run(fmt.Sprintf("n=%d/parallel-%d", n, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					SlowsortParallel(ctx, slices.Clone(input), workers)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sortoftest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macie/sortof"
+)
+
+// conformanceTimeout is the time limit of every sorting in RunConformance,
+// so an algorithm which never finishes fails instead of blocking tests.
+const conformanceTimeout = 10 * time.Second
+
+// errConformance is the cause of cancellation in RunConformance.
+var errConformance = errors.New("cancelled by conformance test")
+
+// RunConformance tests whether the algorithm a fulfils the contract of
+// sortof.Algorithm. Every call of a.Sort gets options opts, e.g. to make
+// sorting finish in reasonable time. Inputs are short, so even pessimal
+// algorithms can sort them. It checks that:
+//   - sorted data is in ascending order as determined by the cmp function,
+//     including NaNs ordered before other floating-point numbers,
+//   - sorted data is a permutation of input, or its subsequence for
+//     filtering algorithms,
+//   - equal elements keep their original order for stable algorithms,
+//   - sorting with an already cancelled context returns an error wrapping
+//     context.Cause and leaves a permutation of input.
+func RunConformance(t *testing.T, a sortof.Algorithm, opts ...sortof.Option) {
+	t.Helper()
+
+	t.Run("Int", func(t *testing.T) {
+		for _, x := range [][]int{
+			{},
+			{1},
+			{2, 1},
+			{3, 1, 2, 1},
+			{5, 4, 3, 2, 1},
+			{math.MaxInt, 0, math.MinInt, -1, 1},
+		} {
+			checkSort(t, a, x, cmp.Compare[int], opts)
+		}
+	})
+	t.Run("Float", func(t *testing.T) {
+		nan := math.NaN()
+		for _, x := range [][]float64{
+			{1.5, -2, 0.25},
+			{nan, 1, nan, 0},
+			{math.Inf(1), -0.0, nan, 0, math.Inf(-1)},
+		} {
+			checkSort(t, a, x, cmp.Compare[float64], opts)
+		}
+	})
+	t.Run("String", func(t *testing.T) {
+		for _, x := range [][]string{
+			{"b", "", "a", "ab", "B"},
+			{"ą", "a", "z"},
+		} {
+			checkSort(t, a, x, strings.Compare, opts)
+		}
+	})
+	t.Run("CustomCmp", func(t *testing.T) {
+		descending := func(a, b string) int { return strings.Compare(b, a) }
+		checkSort(t, a, []string{"b", "c", "a", "c"}, descending, opts)
+
+		byLength := func(a, b string) int { return cmp.Compare(len(a), len(b)) }
+		checkSort(t, a, []string{"ccc", "a", "bb", "dd"}, byLength, opts)
+	})
+	if a.Stable {
+		t.Run("Stable", func(t *testing.T) {
+			type element struct{ key, pos int }
+			byKey := func(a, b element) int { return cmp.Compare(a.key, b.key) }
+			x := []element{{2, 0}, {1, 1}, {2, 2}, {1, 3}, {0, 4}, {2, 5}}
+
+			got := checkSort(t, a, x, byKey, opts)
+			for i := 1; i < len(got); i++ {
+				if got[i].key == got[i-1].key && got[i].pos < got[i-1].pos {
+					t.Errorf("%v.Sort(%v) is not stable; got %v", a, x, got)
+					break
+				}
+			}
+		})
+	}
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errConformance)
+		x := []int{3, 1, 4, 1, 5, 2}
+		data := slices.Clone(x)
+
+		_, err := a.Sort(ctx, sortof.WrapSlice(data, cmp.Compare[int]), opts...)
+		if !errors.Is(err, errConformance) {
+			t.Errorf("%v.Sort(%v) with cancelled context returns error: %v, want %v", a, x, err, errConformance)
+		}
+		if !isPermutation(data, x, cmp.Compare[int]) {
+			t.Errorf("%v.Sort(%v) with cancelled context leaves %v, want permutation of input", a, x, data)
+		}
+	})
+}
+
+// checkSort sorts a copy of x with the algorithm a and reports problems with
+// the result, which is returned.
+func checkSort[E any](t *testing.T, a sortof.Algorithm, x []E, cmp func(a, b E) int, opts []sortof.Option) []E {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	got, err := sortof.SortFunc(ctx, a, slices.Clone(x), cmp, opts...)
+	if err != nil {
+		t.Errorf("%v.Sort(%v) returns error: %v", a, x, err)
+	}
+	if !slices.IsSortedFunc(got, cmp) {
+		t.Errorf("%v.Sort(%v) cannot sort; got %v", a, x, got)
+	}
+	if a.Filtering && !isSubsequence(got, x, cmp) {
+		t.Errorf("%v.Sort(%v) = %v, want subsequence of input", a, x, got)
+	}
+	if !a.Filtering && !isPermutation(got, x, cmp) {
+		t.Errorf("%v.Sort(%v) = %v, want permutation of input", a, x, got)
+	}
+
+	return got
+}
+
+// isPermutation reports whether x and y contain equal elements as
+// determined by the cmp function.
+func isPermutation[E any](x, y []E, cmp func(a, b E) int) bool {
+	x, y = slices.Clone(x), slices.Clone(y)
+	slices.SortFunc(x, cmp)
+	slices.SortFunc(y, cmp)
+
+	return slices.EqualFunc(x, y, func(a, b E) bool { return cmp(a, b) == 0 })
+}
+
+// isSubsequence reports whether elements of x are equal to elements of y in
+// the same order with some elements of y removed.
+func isSubsequence[E any](x, y []E, cmp func(a, b E) int) bool {
+	for _, v := range y {
+		if len(x) > 0 && cmp(x[0], v) == 0 {
+			x = x[1:]
+		}
+	}
+
+	return len(x) == 0
+}
@@ -0,0 +1,66 @@
+package sortoftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/macie/sortof"
+)
+
+func TestRunConformanceReference(t *testing.T) {
+	reference := sortof.Algorithm{
+		Name:   "reference",
+		Stable: true,
+		Sort: func(ctx context.Context, data sortof.Interface, opts ...sortof.Option) (int, error) {
+			if err := context.Cause(ctx); err != nil {
+				return 0, err
+			}
+			// insertion sort
+			for i := 1; i < data.Len(); i++ {
+				for j := i; j > 0 && data.Compare(j, j-1) < 0; j-- {
+					data.Swap(j, j-1)
+				}
+			}
+
+			return data.Len(), nil
+		},
+	}
+
+	RunConformance(t, reference)
+}
+
+func TestIsPermutation(t *testing.T) {
+	compare := func(a, b int) int { return a - b }
+	testcases := []struct {
+		x, y []int
+		want bool
+	}{
+		{nil, []int{}, true},
+		{[]int{1, 2, 2}, []int{2, 1, 2}, true},
+		{[]int{1, 2}, []int{2, 1, 2}, false},
+		{[]int{1, 1, 2}, []int{2, 1, 2}, false},
+	}
+	for _, tc := range testcases {
+		if got := isPermutation(tc.x, tc.y, compare); got != tc.want {
+			t.Errorf("isPermutation(%v, %v) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestIsSubsequence(t *testing.T) {
+	compare := func(a, b int) int { return a - b }
+	testcases := []struct {
+		x, y []int
+		want bool
+	}{
+		{nil, []int{3, 1}, true},
+		{[]int{1, 3}, []int{1, 2, 3}, true},
+		{[]int{3, 1}, []int{1, 2, 3}, false},
+		{[]int{1, 1}, []int{1, 2}, false},
+	}
+	for _, tc := range testcases {
+		if got := isSubsequence(tc.x, tc.y, compare); got != tc.want {
+			t.Errorf("isSubsequence(%v, %v) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package sortoftest implements utilities for testing sorting algorithms and
+// their sources of randomness.
+package sortoftest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/macie/sortof"
+)
+
+// uniformityZ is the quantile of the standard normal distribution for
+// the significance level of CheckUniformShuffle, which is 10^-6.
+const uniformityZ = 4.753
+
+// minExpected is the least expected number of occurrences of each
+// permutation for which the chi-square test is reliable.
+const minExpected = 5
+
+// maxShuffled is the greatest number of shuffled elements, because
+// the number of permutations grows too quickly for the test.
+const maxShuffled = 8
+
+// CheckUniformShuffle shuffles n elements with s trials times and checks
+// with the chi-square test whether every permutation is equally likely.
+// It also checks that s swaps only elements in the range [0, n). It returns
+// an error describing the first problem found.
+//
+// The significance level of the test is 10^-6, so a uniform Shuffler with
+// random seed fails the check very rarely. Each of n! permutations should be
+// expected at least 5 times, and n must be from 2 to 8. For large number of
+// trials the test detects even a small bias.
+func CheckUniformShuffle(s sortof.Shuffler, n, trials int) error {
+	if n < 2 || n > maxShuffled {
+		return fmt.Errorf("cannot check shuffles of %d elements, want from 2 to %d", n, maxShuffled)
+	}
+	perms := factorial(n)
+	if trials < minExpected*perms {
+		return fmt.Errorf("%d trials are too few for %d permutations, want at least %d", trials, perms, minExpected*perms)
+	}
+
+	counts := make([]int, perms)
+	x := make([]int, n)
+	for t := 0; t < trials; t++ {
+		for i := range x {
+			x[i] = i
+		}
+		var err error
+		s.Shuffle(n, func(i, j int) {
+			if i < 0 || i >= n || j < 0 || j >= n {
+				err = fmt.Errorf("shuffle of %d elements swaps indices %d and %d", n, i, j)
+				return
+			}
+			x[i], x[j] = x[j], x[i]
+		})
+		if err != nil {
+			return err
+		}
+		counts[rank(x)]++
+	}
+
+	expected := float64(trials) / float64(perms)
+	chi2 := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	df := perms - 1
+	if critical := chiSquareCritical(float64(df)); chi2 > critical {
+		return fmt.Errorf("shuffles of %d elements are not uniform: chi-square statistic %.2f exceeds critical value %.2f for %d degrees of freedom",
+			n, chi2, critical, df)
+	}
+
+	return nil
+}
+
+// chiSquareCritical returns the critical value of the chi-square
+// distribution with df degrees of freedom for the significance level of
+// CheckUniformShuffle. It uses the Wilson-Hilferty approximation, which
+// slightly overestimates the value for few degrees of freedom.
+func chiSquareCritical(df float64) float64 {
+	v := 2 / (9 * df)
+
+	return df * math.Pow(1-v+uniformityZ*math.Sqrt(v), 3)
+}
+
+// rank returns the position of the permutation x of numbers from 0 to
+// len(x)-1 in the lexicographic order of all permutations.
+func rank(x []int) int {
+	r := 0
+	for i := range x {
+		smaller := 0
+		for _, v := range x[i+1:] {
+			if v < x[i] {
+				smaller++
+			}
+		}
+		r = r*(len(x)-i) + smaller
+	}
+
+	return r
+}
+
+// factorial returns n!.
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+
+	return f
+}
@@ -0,0 +1,84 @@
+package sortoftest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// shuffleFunc is a Shuffler implemented by a function.
+type shuffleFunc func(n int, swap func(i, j int))
+
+func (f shuffleFunc) Shuffle(n int, swap func(i, j int)) { f(n, swap) }
+
+func TestCheckUniformShuffle(t *testing.T) {
+	for n := 2; n <= 5; n++ {
+		n := n
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			t.Parallel()
+			r := rand.New(rand.NewSource(int64(n)))
+
+			if err := CheckUniformShuffle(r, n, 200*factorial(n)); err != nil {
+				t.Errorf("CheckUniformShuffle(rand.Rand, %d, %d) returns error: %v", n, 200*factorial(n), err)
+			}
+		})
+	}
+}
+
+func TestCheckUniformShuffleBiased(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	testcases := map[string]shuffleFunc{
+		"naive": func(n int, swap func(i, j int)) {
+			for i := 0; i < n; i++ {
+				swap(i, r.Intn(n))
+			}
+		},
+		"sattolo": func(n int, swap func(i, j int)) {
+			for i := n - 1; i > 0; i-- {
+				swap(i, r.Intn(i))
+			}
+		},
+		"identity": func(n int, swap func(i, j int)) {},
+	}
+	for name, tc := range testcases {
+		if err := CheckUniformShuffle(tc, 3, 60000); err == nil || !strings.Contains(err.Error(), "not uniform") {
+			t.Errorf("CheckUniformShuffle(%s, 3, 60000) returns error: %v, want non-uniformity", name, err)
+		}
+	}
+}
+
+func TestCheckUniformShuffleInvalid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	outOfRange := shuffleFunc(func(n int, swap func(i, j int)) { swap(0, n) })
+	testcases := map[string]struct {
+		s         shuffleFunc
+		n, trials int
+	}{
+		"single element":     {r.Shuffle, 1, 100},
+		"too many elements":  {r.Shuffle, 9, 1 << 30},
+		"too few trials":     {r.Shuffle, 4, 100},
+		"index out of range": {outOfRange, 2, 100},
+	}
+	for name, tc := range testcases {
+		if err := CheckUniformShuffle(tc.s, tc.n, tc.trials); err == nil {
+			t.Errorf("CheckUniformShuffle(%s, %d, %d) returns no error", name, tc.n, tc.trials)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	testcases := map[int][]int{
+		0: {0, 1, 2},
+		1: {0, 2, 1},
+		2: {1, 0, 2},
+		3: {1, 2, 0},
+		4: {2, 0, 1},
+		5: {2, 1, 0},
+	}
+	for want, tc := range testcases {
+		if got := rank(tc); got != want {
+			t.Errorf("rank(%v) = %d, want %d", tc, got, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package sortof
+
+// indexed is an element of slice with its original position.
+type indexed[E any] struct {
+	value E
+	index int
+}
+
+// stableFunc sorts the slice x with the sorting function sort, so equal
+// elements (as determined by the cmp function) keep their original order.
+// Elements are sorted together with their original positions, which are used
+// as a tie-breaker. Slice x is updated even if sort returns an error.
+func stableFunc[S ~[]E, E any](x S, cmp func(a, b E) int, sort func(y []indexed[E], cmp func(a, b indexed[E]) int) error) error {
+	y := make([]indexed[E], len(x))
+	for i := range x {
+		y[i] = indexed[E]{value: x[i], index: i}
+	}
+
+	err := sort(y, func(a, b indexed[E]) int {
+		if c := cmp(a.value, b.value); c != 0 {
+			return c
+		}
+		switch {
+		case a.index < b.index:
+			return -1
+		case a.index > b.index:
+			return 1
+		}
+		return 0
+	})
+
+	for i := range y {
+		x[i] = y[i].value
+	}
+
+	return err
+}
@@ -3,6 +3,7 @@ package sortof
 import (
 	"cmp"
 	"context"
+	"time"
 )
 
 // Stalinsort returns slice created from x by deleting elements which are not
@@ -23,20 +24,45 @@ func Stalinsort[S ~[]E, E cmp.Ordered](ctx context.Context, x S) (S, error) {
 // cmp(a, b) should return a negative number when a < b, a positive number
 // when a > b and zero when a == b.
 //
+// The sort is stable, because remaining elements keep their original order.
+//
 // See https://mastodon.social/@mathew/100958177234287431.
 func StalinsortFunc[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int) (S, error) {
+	return StalinsortWith(ctx, x, cmp)
+}
+
+// StalinsortWith returns slice created from slice x by deleting elements which
+// are not in order determined by the cmp function with settings from opts.
+// Applicable options are WithStats, WithObserver, WithBudget and
+// WithComparatorCheck. For compatibility with other functions from package,
+// context controls cancellation.
+//
+// See https://mastodon.social/@mathew/100958177234287431.
+func StalinsortWith[S ~[]E, E any](ctx context.Context, x S, cmp func(a, b E) int, opts ...Option) (S, error) {
+	o := newOptions(opts)
+	defer o.stats.since(time.Now())
+	ctx, cmp, o, cancel := prepare(ctx, cmp, o)
+	defer cancel()
+	compare := observedCompare(x, cmp, o.observers)
+
 	sorted := make(S, 0)
+	last := -1 // index of the last kept element
 	for i := range x {
 		select {
 		case <-ctx.Done():
 			return nil, context.Cause(ctx)
 		default:
-			prev := len(sorted) - 1
-			if (i == 0) || (cmp(x[i], sorted[prev]) != -1) {
-				sorted = append(sorted, x[i])
+			if (last >= 0) && (compare(i, last) < 0) {
+				o.observers.OnPurge(i)
+				continue
 			}
+			sorted = append(sorted, x[i])
+			last = i
 		}
 	}
+	if err := inconsistency(ctx); err != nil {
+		return nil, err
+	}
 
 	return sorted, nil
 }
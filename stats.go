@@ -0,0 +1,56 @@
+package sortof
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Stats describes how much work was done by a sorting function (see
+// WithStats). It is an Observer which counts events. Counters are updated
+// atomically, so they are correct also for concurrent workers, but they
+// should be read only after sorting returns. Counters which are not
+// applicable to the algorithm stay zero. Sorting with the same Stats multiple
+// times adds up the work.
+type Stats struct {
+	Comparisons int64         // calls of the comparison function
+	Swaps       int64         // exchanges of two elements
+	Shuffles    int64         // random permutations of the slice (Bogosort)
+	Calls       int64         // recursive calls (Slowsort)
+	Sleeps      int64         // waits for a miracle (Miraclesort)
+	Fallbacks   int64         // results produced by the fallback (see WithFallback)
+	Duration    time.Duration // wall time of sorting
+}
+
+// String returns the summary of all counters.
+func (s Stats) String() string {
+	return fmt.Sprintf("comparisons: %d, swaps: %d, shuffles: %d, calls: %d, sleeps: %d, fallbacks: %d, time: %v",
+		s.Comparisons, s.Swaps, s.Shuffles, s.Calls, s.Sleeps, s.Fallbacks, s.Duration)
+}
+
+// OnCompare counts comparisons.
+func (s *Stats) OnCompare(i, j, result int) { atomic.AddInt64(&s.Comparisons, 1) }
+
+// OnSwap counts swaps.
+func (s *Stats) OnSwap(i, j int) { atomic.AddInt64(&s.Swaps, 1) }
+
+// OnShuffle counts shuffles.
+func (s *Stats) OnShuffle(iteration int) { atomic.AddInt64(&s.Shuffles, 1) }
+
+// OnPurge does nothing, because deleted elements are not counted.
+func (s *Stats) OnPurge(index int) {}
+
+// OnRecurse counts recursive calls.
+func (s *Stats) OnRecurse(i, j, depth int) { atomic.AddInt64(&s.Calls, 1) }
+
+// OnSleep counts waits.
+func (s *Stats) OnSleep() { atomic.AddInt64(&s.Sleeps, 1) }
+
+// since adds the time elapsed since start to the duration of sorting. It does
+// nothing if s is nil.
+func (s *Stats) since(start time.Time) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64((*int64)(&s.Duration), int64(time.Since(start)))
+}
@@ -0,0 +1,88 @@
+package sortof
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithStats(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 2, 5, 4}
+	testcases := map[string]struct {
+		sort    func(x []int, stats *Stats) error
+		nonZero func(s Stats) []int64
+		zero    func(s Stats) []int64
+	}{
+		"BogosortWith": {
+			sort: func(x []int, stats *Stats) error {
+				return BogosortWith(ctx, x, cmp.Compare[int], WithSeed(1), WithStats(stats))
+			},
+			nonZero: func(s Stats) []int64 { return []int64{s.Comparisons, s.Swaps, s.Shuffles} },
+			zero:    func(s Stats) []int64 { return []int64{s.Calls, s.Sleeps} },
+		},
+		"SlowsortWith": {
+			sort: func(x []int, stats *Stats) error {
+				return SlowsortWith(ctx, x, cmp.Compare[int], WithStats(stats))
+			},
+			nonZero: func(s Stats) []int64 { return []int64{s.Comparisons, s.Swaps, s.Calls} },
+			zero:    func(s Stats) []int64 { return []int64{s.Shuffles, s.Sleeps} },
+		},
+		"StalinsortWith": {
+			sort: func(x []int, stats *Stats) error {
+				_, err := StalinsortWith(ctx, x, cmp.Compare[int], WithStats(stats))
+				return err
+			},
+			nonZero: func(s Stats) []int64 { return []int64{s.Comparisons} },
+			zero:    func(s Stats) []int64 { return []int64{s.Swaps, s.Shuffles, s.Calls, s.Sleeps} },
+		},
+	}
+	for name, sorter := range testcases {
+		name, sorter := name, sorter
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var stats Stats
+
+			if err := sorter.sort(slices.Clone(tc), &stats); err != nil {
+				t.Fatalf("%s(%v, %v, cmp.Compare, WithStats) returns error: %v", name, ctx, tc, err)
+			}
+			for _, n := range sorter.nonZero(stats) {
+				if n == 0 {
+					t.Errorf("%s(%v, %v, cmp.Compare, WithStats) records %v, want non-zero counters of done work", name, ctx, tc, stats)
+				}
+			}
+			for _, n := range sorter.zero(stats) {
+				if n != 0 {
+					t.Errorf("%s(%v, %v, cmp.Compare, WithStats) records %v, want zero counters of not applicable work", name, ctx, tc, stats)
+				}
+			}
+		})
+	}
+}
+
+func TestWithStatsMiraclesort(t *testing.T) {
+	ctx := context.Background()
+	tc := []int{3, 1, 2}
+	x := slices.Clone(tc)
+	clock := newFakeClock()
+	var stats Stats
+
+	done := make(chan error)
+	go func() {
+		done <- MiraclesortWith(ctx, x, cmp.Compare[int], WithClock(clock), WithStats(&stats))
+	}()
+	<-clock.waiting
+	clock.ticks <- time.Time{} // no miracle yet
+	<-clock.waiting
+	slices.Sort(x) // miracle
+	clock.ticks <- time.Time{}
+
+	if err := <-done; err != nil {
+		t.Errorf("MiraclesortWith(%v, %v, cmp.Compare, WithStats) returns error: %v", ctx, tc, err)
+	}
+	if stats.Sleeps != 2 || stats.Swaps != 0 {
+		t.Errorf("MiraclesortWith(%v, %v, cmp.Compare, WithStats) records %v, want 2 sleeps and no swaps", ctx, tc, stats)
+	}
+}